@@ -0,0 +1,152 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+var ErrInvalidQuorum = errors.New("signature does not carry a valid quorum")
+
+/*
+* Accountable threshold signatures for a group of independently keyed parties.
+* Boldyreva pKeys can not be used: they are Shamir shares of one key, so any
+* t+1 partials interpolate H(m)^{s_j} for every party j and a combiner could
+* name any quorum it likes. Here every party draws its own key with
+* GenMultiSigParty and registers it with a proof of possession. The combiner
+* adds up t+1 partial signatures and records who signed in a bitmap; the
+* result verifies as a BLS signature under the sum of the quorum's keys:
+*
+*	e(prod_{i in S} pKey_i, H(m)) = e(g1, prod_{i in S} sigma_i)
+*
+* The keys are independent and proofs of possession stop rogue keys, so naming
+* a party in S that did not sign requires forging a signature under its key.
+ */
+
+type Accountable struct {
+	b BLS
+}
+
+// NewAccountable sets up a group in which any t+1 of the parties' keys sign
+func NewAccountable(crs BLSCRS, t int, pKeys []bls.G1Affine, pops []bls.G2Jac) (Accountable, error) {
+	n := len(pKeys)
+	if t < 0 || t >= n {
+		return Accountable{}, ErrInvalidQuorum
+	}
+	a := Accountable{
+		b: BLS{
+			n:   n,
+			t:   t,
+			crs: crs,
+			pp:  BLSParams{pKeys: append([]bls.G1Affine{}, pKeys...)},
+		},
+	}
+	if _, err := a.b.aggregateKeys(pKeys, pops); err != nil {
+		return Accountable{}, err
+	}
+	return a, nil
+}
+
+func (a *Accountable) SetPolicy(p SignPolicy) {
+	a.b.SetPolicy(p)
+}
+
+// Plain BLS signature of a party under its own key
+func (a *Accountable) psign(msg Message, signer BLSParty) (bls.G2Jac, error) {
+	return a.b.psign(msg, signer)
+}
+
+// AccountableSig is encoded as Sigma (96 bytes) || Signers
+type AccountableSig struct {
+	Signers []byte // bitmap, party i is bit i%8 of byte i/8
+	Sigma   bls.G2Affine
+}
+
+func (s *AccountableSig) Bytes() []byte {
+	sigBytes := s.Sigma.Bytes()
+	return append(sigBytes[:], s.Signers...)
+}
+
+func (s *AccountableSig) SetBytes(data []byte) error {
+	if len(data) < bls.SizeOfG2AffineCompressed {
+		return ErrInvalidQuorum
+	}
+	if _, err := s.Sigma.SetBytes(data[:bls.SizeOfG2AffineCompressed]); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuorum, err)
+	}
+	s.Signers = append([]byte{}, data[bls.SizeOfG2AffineCompressed:]...)
+	return nil
+}
+
+// Quorum lists the signer indices in increasing order
+func (s *AccountableSig) Quorum() []int {
+	var quorum []int
+	for i, v := range s.Signers {
+		for ; v != 0; v &= v - 1 {
+			quorum = append(quorum, 8*i+bits.TrailingZeros8(v))
+		}
+	}
+	return quorum
+}
+
+// Verifies the partial signatures and aggregates the first t+1 valid ones
+func (a *Accountable) combine(msg Message, signers []int, sigmas []bls.G2Jac) (AccountableSig, error) {
+	b := &a.b
+	roMsg, err := b.hashMsg(msg)
+	if err != nil {
+		return AccountableSig{}, err
+	}
+
+	sig := AccountableSig{Signers: make([]byte, (b.n+7)/8)}
+	var agg bls.G2Jac
+	count := 0
+	for i, idx := range signers {
+		if idx < 0 || idx >= b.n || sig.Signers[idx/8]&(1<<(idx%8)) != 0 {
+			continue
+		}
+		if b.pverify(roMsg, sigmas[i], b.pp.pKeys[idx]) {
+			sig.Signers[idx/8] |= 1 << (idx % 8)
+			agg.AddAssign(&sigmas[i])
+			count++
+			if count == b.t+1 {
+				break
+			}
+		}
+	}
+	if count <= b.t {
+		return AccountableSig{}, ErrCombineFailed
+	}
+
+	sig.Sigma.FromJacobian(&agg)
+	return sig, nil
+}
+
+// Checks that exactly t+1 registered parties produced sig and returns them
+func (a *Accountable) verify(msg Message, sig AccountableSig) ([]int, error) {
+	b := &a.b
+	if len(sig.Signers) != (b.n+7)/8 {
+		return nil, ErrInvalidQuorum
+	}
+	quorum := sig.Quorum()
+	if len(quorum) != b.t+1 || quorum[len(quorum)-1] >= b.n {
+		return nil, ErrInvalidQuorum
+	}
+	if !sig.Sigma.IsInSubGroup() {
+		return nil, ErrInvalidQuorum
+	}
+
+	var aggKey bls.G1Jac
+	for _, idx := range quorum {
+		aggKey.AddMixed(&b.pp.pKeys[idx])
+	}
+	roMsg, err := b.hashMsg(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !b.pverify(roMsg, *new(bls.G2Jac).FromAffine(&sig.Sigma), *new(bls.G1Affine).FromJacobian(&aggKey)) {
+		return nil, ErrInvalidQuorum
+	}
+	return quorum, nil
+}
@@ -0,0 +1,150 @@
+package tss
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func genAccountable(t *testing.T, crs BLSCRS, n, ths int) (Accountable, []BLSParty) {
+	parties, pKeys := genMultiSigGroup(crs, n)
+	pops := make([]bls.G2Jac, n)
+	for i := range parties {
+		var err error
+		pops[i], err = GenMultiSigPop(crs, parties[i])
+		assert.NoError(t, err)
+	}
+	a, err := NewAccountable(crs, ths, pKeys, pops)
+	assert.NoError(t, err)
+	return a, parties
+}
+
+func TestAccountable(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n / 2
+	crs := GenBLSCRS(n)
+	a, parties := genAccountable(t, crs, n, ths)
+
+	// Parties 1..t+2 sign, party 1 sends garbage
+	var signers []int
+	var sigmas []bls.G2Jac
+	for i := 1; i <= ths+2; i++ {
+		sigma, _ := a.psign(msg, parties[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+	}
+	sigmas[0] = sigmas[1]
+
+	sig, err := a.combine(msg, signers, sigmas)
+	assert.NoError(t, err)
+	assert.Equal(t, signers[1:], sig.Quorum())
+
+	quorum, err := a.verify(msg, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, signers[1:], quorum)
+
+	// Round trip through the encoding
+	var dec AccountableSig
+	assert.NoError(t, dec.SetBytes(sig.Bytes()))
+	quorum, err = a.verify(msg, dec)
+	assert.NoError(t, err)
+	assert.Equal(t, signers[1:], quorum)
+
+	_, err = a.verify([]byte("other"), sig)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+
+	// Claiming a different quorum fails
+	framed := AccountableSig{Signers: append([]byte{}, sig.Signers...), Sigma: sig.Sigma}
+	framed.Signers[0] ^= 1<<0 | 1<<2
+	_, err = a.verify(msg, framed)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+
+	// Quorums must have exactly t+1 members
+	framed.Signers[0] ^= 1 << 2
+	_, err = a.verify(msg, framed)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+
+	// Duplicates do not count towards the quorum
+	_, err = a.combine(msg, append(signers[1:ths+1], signers[1]), append(sigmas[1:ths+1], sigmas[1]))
+	assert.ErrorIs(t, err, ErrCombineFailed)
+}
+
+// Interpolating t+1 partials at the other parties' indices frames them when
+// keys are Shamir shares, but not when they are independent
+func TestAccountableFraming(t *testing.T) {
+	msg := []byte("hello world")
+
+	// Two disjoint quorums: 0..t sign, t+1..n-1 are framed
+	n := 1 << 4
+	ths := n/2 - 1
+	crs := GenBLSCRS(n)
+	shamir := NewBLS(n, ths, crs)
+	a, parties := genAccountable(t, crs, n, ths)
+	roMsg, _ := shamir.hashMsg(msg)
+
+	honest := make([]int, ths+1)
+	for i := range honest {
+		honest[i] = i
+	}
+	victims := make([]int, ths+1)
+	for i := range victims {
+		victims[i] = ths + 1 + i
+	}
+	omegas := RootsOfUnity(uint64(n))
+	frame := func(sigmas []bls.G2Affine) bls.G2Affine {
+		var agg bls.G2Jac
+		for _, j := range victims {
+			var sigma bls.G2Jac
+			sigma.MultiExp(sigmas, GetLagAt(uint64(n), omegas[j], honest), ecc.MultiExpConfig{})
+			agg.AddAssign(&sigma)
+		}
+		return *new(bls.G2Affine).FromJacobian(&agg)
+	}
+
+	shamirSigmas := make([]bls.G2Affine, len(honest))
+	accSigmas := make([]bls.G2Affine, len(honest))
+	for i, idx := range honest {
+		sigma, _ := shamir.psign(msg, shamir.pp.signers[idx])
+		shamirSigmas[i].FromJacobian(&sigma)
+		sigma, _ = a.psign(msg, parties[idx])
+		accSigmas[i].FromJacobian(&sigma)
+	}
+
+	var shamirKey bls.G1Jac
+	for _, j := range victims {
+		shamirKey.AddMixed(&shamir.pp.pKeys[j])
+	}
+	forged := frame(shamirSigmas)
+	assert.True(t, shamir.pverify(roMsg, *new(bls.G2Jac).FromAffine(&forged), *new(bls.G1Affine).FromJacobian(&shamirKey)))
+
+	framed := AccountableSig{Signers: make([]byte, (n+7)/8), Sigma: frame(accSigmas)}
+	for _, j := range victims {
+		framed.Signers[j/8] |= 1 << (j % 8)
+	}
+	_, err := a.verify(msg, framed)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+}
+
+func TestAccountableSetup(t *testing.T) {
+	n := 4
+	crs := GenBLSCRS(n)
+	parties, pKeys := genMultiSigGroup(crs, n)
+	pops := make([]bls.G2Jac, n)
+	for i := range parties {
+		pops[i], _ = GenMultiSigPop(crs, parties[i])
+	}
+
+	_, err := NewAccountable(crs, n, pKeys, pops)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+	_, err = NewAccountable(crs, -1, pKeys, pops)
+	assert.ErrorIs(t, err, ErrInvalidQuorum)
+
+	// Every key needs its own proof of possession
+	pops[1] = pops[0]
+	_, err = NewAccountable(crs, 1, pKeys, pops)
+	assert.ErrorIs(t, err, ErrInvalidPoP)
+}
@@ -30,6 +30,8 @@ type ABLSCRS struct {
 	g2a     bls.G2Affine
 	domain  *fft.Domain
 	H       []fr.Element
+	fp      [32]byte
+	fb      fixedBases
 }
 
 type ABLSParams struct {
@@ -39,16 +41,22 @@ type ABLSParams struct {
 }
 
 type ABLS struct {
-	n   int
-	t   int
-	crs ABLSCRS
-	pp  ABLSParams
+	n      int
+	t      int
+	crs    ABLSCRS
+	pp     ABLSParams
+	policy SignPolicy
 }
 
 func (b *ABLS) getParamsAff() []bls.G1Affine {
 	return []bls.G1Affine{b.crs.g1a, b.crs.h1a, b.crs.v1a}
 }
 
+// g1^s h1^r v1^u, trailing scalars may be left out
+func (b *ABLS) commitG1(scalars ...fr.Element) bls.G1Jac {
+	return b.crs.fb.multiExp(b.getParamsAff(), scalars...)
+}
+
 func GenABLSCRS(n int) ABLSCRS {
 	domain := fft.NewDomain(uint64(n))
 
@@ -74,7 +82,7 @@ func GenABLSCRS(n int) ABLSCRS {
 	g2 := *new(bls.G2Jac).ScalarMultiplication(&gen2, s2.BigInt(&big.Int{}))
 	g1Inv := *new(bls.G1Jac).Neg(&g1)
 
-	return ABLSCRS{
+	crs := ABLSCRS{
 		g1:      g1,
 		h1:      h1,
 		v1:      v1,
@@ -88,6 +96,19 @@ func GenABLSCRS(n int) ABLSCRS {
 		domain:  domain,
 		H:       H,
 	}
+	crs.fp = crsFingerprint("ABLS-CRS", []bls.G1Affine{crs.g1a, crs.h1a, crs.v1a}, []bls.G2Affine{crs.g2a}, n, nil)
+	crs.fb = newFixedBases(DefaultFixedBaseWindow, crs.g1a, crs.h1a, crs.v1a)
+	return crs
+}
+
+// SetFixedBaseWindow rebuilds the tables of g1, h1 and v1 with windows of w
+// bits, 1 <= w <= MaxFixedBaseWindow
+func (crs *ABLSCRS) SetFixedBaseWindow(w int) error {
+	if err := validWindow(w); err != nil {
+		return err
+	}
+	crs.fb = newFixedBases(w, crs.g1a, crs.h1a, crs.v1a)
+	return nil
 }
 
 // Here t is the degree of the polynomial
@@ -103,6 +124,11 @@ func NewABLS(n, t int, crs ABLSCRS) ABLS {
 	return bls
 }
 
+// SetPolicy installs the policy every partial signing request must pass
+func (b *ABLS) SetPolicy(p SignPolicy) {
+	b.policy = p
+}
+
 // (n,t) secret shared keys
 func (b *ABLS) keyGen() {
 	sKeys := make([]fr.Element, b.n)
@@ -119,7 +145,7 @@ func (b *ABLS) keyGen() {
 	rKeys[0].SetZero()
 	uKeys[0].SetZero()
 
-	pk := *new(bls.G1Jac).ScalarMultiplication(&b.crs.g1, sKeys[0].BigInt(&big.Int{}))
+	pk := b.commitG1(sKeys[0])
 	pkAf := *new(bls.G1Affine).FromJacobian(&pk)
 
 	b.crs.domain.FFT(sKeys, fft.DIF)
@@ -131,7 +157,7 @@ func (b *ABLS) keyGen() {
 
 	parties := make([]ABLSParty, b.n)
 	for i := 0; i < b.n; i++ {
-		pKeys[i].MultiExp(b.getParamsAff(), []fr.Element{sKeys[i], rKeys[i], uKeys[i]}, ecc.MultiExpConfig{})
+		pKeys[i] = b.commitG1(sKeys[i], rKeys[i], uKeys[i])
 		parties[i] = ABLSParty{
 			sKey:  sKeys[i],
 			rKey:  rKeys[i],
@@ -157,93 +183,144 @@ type SigmaPf struct {
 }
 
 // Computing the Chaum-Pedersen Sigma protocol
-func (b *ABLS) sigmaProve(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, sigma bls.G2Jac, signer ABLSParty) SigmaPf {
+func (b *ABLS) sigmaProve(ctx []byte, ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, sigma bls.G2Jac, signer ABLSParty) SigmaPf {
+	pf, c := b.sigmaProveCmt(ctx, ro0Msg, ro1Msg, sigma, signer)
+	return SigmaPf{c, pf.zs, pf.zr, pf.zu}
+}
+
+// Same proof, keeping the commitments instead of the challenge
+func (b *ABLS) sigmaProveCmt(ctx []byte, ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, sigma bls.G2Jac, signer ABLSParty) (SigmaCmtPf, fr.Element) {
 	var (
 		hs, hr, hu fr.Element
-		x          bls.G1Jac
 		y          bls.G2Jac
 	)
 	hs.SetRandom()
 	hr.SetRandom()
 	hu.SetRandom()
 
-	x.MultiExp(b.getParamsAff(), []fr.Element{hs, hr, hu}, ecc.MultiExpConfig{})
+	x := b.commitG1(hs, hr, hu)
 	y.MultiExp([]bls.G2Affine{ro0Msg, ro1Msg}, []fr.Element{hs, hr}, ecc.MultiExpConfig{})
 
-	c := getFSChal([]bls.G1Jac{signer.pKey, x}, []bls.G2Jac{sigma, y})
+	var pf SigmaCmtPf
+	pf.x.FromJacobian(&x)
+	pf.y.FromJacobian(&y)
+
+	pKey := *new(bls.G1Affine).FromJacobian(&signer.pKey)
+	sigmaAf := *new(bls.G2Affine).FromJacobian(&sigma)
+	c := b.sigmaChallenge(ctx, signer.index, ro0Msg, ro1Msg, pKey, sigmaAf, pf.x, pf.y)
 
-	var zs, zr, zu fr.Element
-	zs.Add(zs.Mul(&c, &signer.sKey), &hs)
-	zr.Add(zr.Mul(&c, &signer.rKey), &hr)
-	zu.Add(zu.Mul(&c, &signer.uKey), &hu)
+	pf.zs.Add(pf.zs.Mul(&c, &signer.sKey), &hs)
+	pf.zr.Add(pf.zr.Mul(&c, &signer.rKey), &hr)
+	pf.zu.Add(pf.zu.Mul(&c, &signer.uKey), &hu)
+
+	return pf, c
+}
 
-	return SigmaPf{c, zs, zr, zu}
+// Binds the proof to the CRS, the group key, the signer and the message
+func (b *ABLS) sigmaChallenge(ctx []byte, index int, ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, pKey bls.G1Affine, sigma bls.G2Affine, x bls.G1Affine, y bls.G2Affine) fr.Element {
+	t := newTranscript("ABLS-PARTIAL-SIGMA")
+	t.appendBytes("crs", b.crs.fp[:])
+	t.appendG1("pk", b.pp.pk)
+	t.appendUint64("index", uint64(index))
+	t.appendBytes("context", ctx)
+	t.appendG2("msg", ro0Msg, ro1Msg)
+	t.appendG1("pkey", pKey)
+	t.appendG2("sigma", sigma)
+	t.appendG1("x", x)
+	t.appendG2("y", y)
+	return t.challenge()
 }
 
 // Checks the correctness of the Chaum-Pedersen Proof
-func (b *ABLS) sigmaVerify(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, pk bls.G1Jac, sigma bls.G2Jac, pf SigmaPf) bool {
+func (b *ABLS) sigmaVerify(ctx []byte, ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, index int, sigma bls.G2Jac, pf SigmaPf) bool {
+	if index < 0 || index >= b.n {
+		return false
+	}
+	pKey := b.pp.pKeys[index]
 
 	cInt := pf.c.BigInt(&big.Int{})
-	pkC := *new(bls.G1Jac).ScalarMultiplication(&pk, cInt)
+	pkC := *new(bls.G1Jac).FromAffine(&pKey)
+	pkC.ScalarMultiplication(&pkC, cInt)
 	sigmaC := *new(bls.G2Jac).ScalarMultiplication(&sigma, cInt)
 
-	var pZ bls.G1Jac
 	var hmZ bls.G2Jac
-	pZ.MultiExp(b.getParamsAff(), []fr.Element{pf.zs, pf.zr, pf.zu}, ecc.MultiExpConfig{})
+	pZ := b.commitG1(pf.zs, pf.zr, pf.zu)
 	hmZ.MultiExp([]bls.G2Affine{ro0Msg, ro1Msg}, []fr.Element{pf.zs, pf.zr}, ecc.MultiExpConfig{})
 
 	pZ.SubAssign(&pkC)
 	hmZ.SubAssign(&sigmaC)
 
-	cLocal := getFSChal([]bls.G1Jac{pk, pZ}, []bls.G2Jac{sigma, hmZ})
+	x := *new(bls.G1Affine).FromJacobian(&pZ)
+	y := *new(bls.G2Affine).FromJacobian(&hmZ)
+	sigmaAf := *new(bls.G2Affine).FromJacobian(&sigma)
+	cLocal := b.sigmaChallenge(ctx, index, ro0Msg, ro1Msg, pKey, sigmaAf, x, y)
 
 	return pf.c.Equal(&cLocal)
 }
 
+// Hashes the message to the two independent generators H0(m) and H1(m)
+func (b *ABLS) hashMsg(msg Message) (bls.G2Affine, bls.G2Affine, error) {
+	ro0Msg, err := bls.HashToG2(msg, []byte("DST0"))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	ro1Msg, err := bls.HashToG2(msg, []byte("DST1"))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	return ro0Msg, ro1Msg, nil
+}
+
 // Partial signature along
-func (b *ABLS) pSign(msg Message, signer ABLSParty) (bls.G2Jac, SigmaPf) {
+func (b *ABLS) pSign(msg Message, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	return b.pSignCtx(nil, msg, signer)
+}
+
+// Partial signature whose proof is additionally bound to ctx
+func (b *ABLS) pSignCtx(ctx []byte, msg Message, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	if err := checkPolicy(b.policy, msg, signer.index); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
 
-	dst0 := []byte("DST0")
-	dst1 := []byte("DST1")
-	ro0Msg, _ := bls.HashToG2(msg, dst0)
-	ro1Msg, _ := bls.HashToG2(msg, dst1)
+	ro0Msg, ro1Msg, err := b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	sigma, pf := b.signPoints(ctx, ro0Msg, ro1Msg, signer)
+	return sigma, pf, nil
+}
+
+// Signs already hashed message points; the caller is responsible for the policy check
+func (b *ABLS) signPoints(ctx []byte, ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, signer ABLSParty) (bls.G2Jac, SigmaPf) {
 	var sigma bls.G2Jac
 
 	sigma.MultiExp([]bls.G2Affine{ro0Msg, ro1Msg}, []fr.Element{signer.sKey, signer.rKey}, ecc.MultiExpConfig{})
-	pf := b.sigmaProve(ro0Msg, ro1Msg, sigma, signer)
+	pf := b.sigmaProve(ctx, ro0Msg, ro1Msg, sigma, signer)
 	return sigma, pf
 }
 
-func (b *ABLS) pVerify(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, sigma bls.G2Jac, vkAf bls.G1Affine, pf SigmaPf) bool {
-	vk := *new(bls.G1Jac).FromAffine(&vkAf)
-	return b.sigmaVerify(ro0Msg, ro1Msg, vk, sigma, pf)
-}
-
-func (b *ABLS) verifyCombine(ro0Msg bls.G2Affine, ro1msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) bls.G2Jac {
-	var vfSigners []int
-	var lIdx []int
-
-	for i, idx := range signers {
-		if b.pVerify(ro0Msg, ro1msg, sigmas[i], b.pp.pKeys[idx], pfs[i]) {
-			vfSigners = append(vfSigners, signers[i])
-			lIdx = append(lIdx, i)
-			if len(lIdx) == b.t+1 {
-				break
-			}
-		}
+// Verifies the partial signature of the party at index
+func (b *ABLS) pVerify(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, sigma bls.G2Jac, index int, pf SigmaPf) bool {
+	if _, err := validG2Jac(&sigma); err != nil {
+		return false
 	}
+	return b.proofVerify(ro0Msg, ro1Msg, sigma, index, pf)
+}
 
-	vfSigs := make([]bls.G2Affine, len(vfSigners))
-	for i, idx := range lIdx {
-		vfSigs[i].FromJacobian(&sigmas[idx])
-	}
+// The proof check alone, for signatures that were already validated
+func (b *ABLS) proofVerify(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, sigma bls.G2Jac, index int, pf SigmaPf) bool {
+	return b.sigmaVerify(nil, ro0Msg, ro1Msg, index, sigma, pf)
+}
 
-	return b.combine(vfSigners, vfSigs)
+// Zero if fewer than t+1 partials verify, verifyCombineResult says why
+func (b *ABLS) verifyCombine(ro0Msg bls.G2Affine, ro1msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) bls.G2Jac {
+	res, _ := b.verifyCombineResult(ro0Msg, ro1msg, signers, sigmas, pfs)
+	return res.Sigma
 }
 
 func (b *ABLS) combine(signers []int, sigmas []bls.G2Affine) bls.G2Jac {
-	// If not enough signatures to combine return a empty value
-	if len(signers) <= b.t {
+	// If not enough valid signatures to combine return a empty value
+	if validQuorum(b.n, b.t, signers, sigmas) != nil {
 		return bls.G2Jac{}
 	}
 
@@ -255,14 +332,16 @@ func (b *ABLS) combine(signers []int, sigmas []bls.G2Affine) bls.G2Jac {
 	lagH := GetLagAt0(uint64(b.n), indices)
 
 	var thSig bls.G2Jac
-	thSig.MultiExp(sigmas, lagH, ecc.MultiExpConfig{})
+	thSig.MultiExp(sigmas[:b.t+1], lagH, ecc.MultiExpConfig{})
 
 	return thSig
 }
 
 func (b *ABLS) gverify(roMsg bls.G2Affine, sigma bls.G2Jac) bool {
-	var sigmaAff bls.G2Affine
-	sigmaAff.FromJacobian(&sigma)
+	sigmaAff, err := validG2Jac(&sigma)
+	if err != nil {
+		return false
+	}
 
 	res, _ := bls.PairingCheck([]bls.G1Affine{b.pp.pk, b.crs.g1InvAf}, []bls.G2Affine{roMsg, sigmaAff})
 	return res
@@ -32,12 +32,11 @@ func TestABLS(t *testing.T) {
 	var pfs []SigmaPf
 	for i := 0; i < ths+1; i++ {
 		signers = append(signers, i)
-		sigma, pf := m.pSign(msg, m.pp.signers[i])
+		sigma, pf, _ := m.pSign(msg, m.pp.signers[i])
 		sigmas = append(sigmas, sigma)
 		pfs = append(pfs, pf)
 
-		pkAff := *new(bls.G1Affine).FromJacobian(&m.pp.signers[i].pKey)
-		if m.pVerify(ro0Msg, ro1Msg, sigma, pkAff, pf) {
+		if m.pVerify(ro0Msg, ro1Msg, sigma, i, pf) {
 			fmt.Println(i)
 		}
 	}
@@ -63,20 +62,24 @@ func BenchmarkABLS(b *testing.B) {
 
 	var sigma bls.G2Jac
 	var pf SigmaPf
-	b.Run("ABLS-pSign", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			sigma, pf = m.pSign(msg, m.pp.signers[0])
-		}
-	})
+	for _, w := range benchWindows {
+		setWindowABLS(&m.crs, w)
+		b.Run(fmt.Sprintf("w=%d", w), func(b *testing.B) {
+			b.Run("ABLS-pSign", func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					sigma, pf, _ = m.pSign(msg, m.pp.signers[0])
+				}
+			})
 
-	pk0Aff := *new(bls.G1Affine).FromJacobian(&m.pp.signers[0].pKey)
-	b.Run("ABLS-pVerify", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			m.pVerify(ro0Msg, ro1Msg, sigma, pk0Aff, pf)
-		}
-	})
+			b.Run("ABLS-pVerify", func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.pVerify(ro0Msg, ro1Msg, sigma, 0, pf)
+				}
+			})
+		})
+	}
 }
 
 func BenchmarkABLSAgg(b *testing.B) {
@@ -103,12 +106,14 @@ func BenchmarkABLSAgg(b *testing.B) {
 		signers := make([]int, tc.t)
 		sigmas := make([]bls.G2Jac, tc.t)
 		pfs := make([]SigmaPf, tc.t)
+		cmtPfs := make([]SigmaCmtPf, tc.t)
 
 		for i := 0; i < tc.t; i++ {
 			signers[i] = i
-			sigma, pf := m.pSign(msg, m.pp.signers[i])
+			sigma, pf, _ := m.pSign(msg, m.pp.signers[i])
 			pfs[i] = pf
 			sigmas[i] = sigma
+			cmtPfs[i], _ = m.sigmaProveCmt(nil, ro0Msg, ro1Msg, sigma, m.pp.signers[i])
 		}
 
 		var sigma bls.G2Jac
@@ -120,6 +125,21 @@ func BenchmarkABLSAgg(b *testing.B) {
 			}
 		})
 
+		b.Run(tc.name+"-ABLS-agg-batch", func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sigma = m.verifyCombineBatch(ro0Msg, ro1Msg, signers, sigmas, cmtPfs)
+			}
+		})
+
+		b.Run(tc.name+"-ABLS-agg-optimistic", func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				res, _ := m.optimisticCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+				sigma = res.Sigma
+			}
+		})
+
 		b.Run(tc.name+"-ABLS-agg-no-verify", func(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
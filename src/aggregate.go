@@ -0,0 +1,156 @@
+package tss
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+var (
+	ErrDuplicateMessage = errors.New("message appears twice in the aggregate")
+	ErrMixedGenerators  = errors.New("keys are defined over different generators")
+)
+
+/*
+* Verification of k combined signatures on distinct messages, under one or
+* several group keys, with k+1 Miller loops and one final exponentiation:
+*
+*	prod_j e(pk_j^rho_j, H(m_j)) = e(g1, prod_j sigma_j^rho_j)
+*
+* The random rho_j make the check hold only if every signature is valid. The
+* signatures can also be added up into one aggregate, which is checked with
+* rho_j = 1. Without proofs of possession for the keys this is only sound when
+* all messages are distinct: a rogue key pk' = g1^x / pk signing the message
+* of pk makes e(pk, H(m)) e(pk', H(m)) = e(g1, H(m)^x) for any x.
+ */
+
+// GroupKey identifies a committee to verifiers that hold no BLS instance; it
+// carries the same data as a VRF key, with the signing DST
+type GroupKey = VRFPublicKey
+
+func (b *BLS) groupKey() GroupKey {
+	return GroupKey{pk: b.pp.pk, g1: b.crs.g1a, dst: b.crs.dst}
+}
+
+func (b *ABLS) groupKey() GroupKey {
+	return GroupKey{pk: b.pp.pk, g1: b.crs.g1a, dst: []byte("DST0")}
+}
+
+type SignedMessage struct {
+	Key   GroupKey
+	Msg   Message
+	Sigma bls.G2Affine
+}
+
+func millerCheck(P []bls.G1Affine, Q []bls.G2Affine) bool {
+	ml, err := bls.MillerLoop(P, Q)
+	if err != nil {
+		return false
+	}
+	res := bls.FinalExponentiation(&ml)
+	return res.IsOne()
+}
+
+// VerifyBatch checks every signature in items; keys over the same generator
+// share a single Miller loop on the signature side
+func VerifyBatch(items []SignedMessage) bool {
+	P := make([]bls.G1Affine, 0, len(items)+1)
+	Q := make([]bls.G2Affine, 0, len(items)+1)
+
+	type group struct {
+		sigmas []bls.G2Affine
+		rhos   []fr.Element
+	}
+	groups := make(map[bls.G1Affine]*group)
+
+	for _, it := range items {
+		if validG2(&it.Sigma) != nil || it.Key.pk.IsInfinity() {
+			return false
+		}
+		roMsg, err := bls.HashToG2(it.Msg, it.Key.dst)
+		if err != nil {
+			return false
+		}
+
+		var rho fr.Element
+		rho.SetRandom()
+
+		var pkRho bls.G1Affine
+		pkRho.ScalarMultiplication(&it.Key.pk, rho.BigInt(&big.Int{}))
+		P = append(P, pkRho)
+		Q = append(Q, roMsg)
+
+		g, ok := groups[it.Key.g1]
+		if !ok {
+			g = &group{}
+			groups[it.Key.g1] = g
+		}
+		g.sigmas = append(g.sigmas, it.Sigma)
+		g.rhos = append(g.rhos, rho)
+	}
+
+	for g1, g := range groups {
+		var g1Inv bls.G1Affine
+		var sum bls.G2Affine
+		g1Inv.Neg(&g1)
+		sum.MultiExp(g.sigmas, g.rhos, ecc.MultiExpConfig{})
+		P = append(P, g1Inv)
+		Q = append(Q, sum)
+	}
+	return millerCheck(P, Q)
+}
+
+// AggregateSignatures adds up combined signatures into one 96-byte value
+func AggregateSignatures(sigmas []bls.G2Affine) bls.G2Affine {
+	var agg bls.G2Jac
+	for i := range sigmas {
+		agg.AddMixed(&sigmas[i])
+	}
+	return *new(bls.G2Affine).FromJacobian(&agg)
+}
+
+// VerifyAggregate checks an aggregate of signatures on msgs[j] under keys[j];
+// the messages must be pairwise distinct whatever the keys
+func VerifyAggregate(keys []GroupKey, msgs []Message, agg bls.G2Affine) error {
+	if len(keys) != len(msgs) || len(keys) == 0 {
+		return ErrCombineFailed
+	}
+	if err := validG2(&agg); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(msgs))
+	P := make([]bls.G1Affine, 0, len(keys)+1)
+	Q := make([]bls.G2Affine, 0, len(keys)+1)
+	for j := range keys {
+		if keys[j].pk.IsInfinity() {
+			return ErrInvalidPoint
+		}
+		if keys[j].g1 != keys[0].g1 {
+			return ErrMixedGenerators
+		}
+		if seen[string(msgs[j])] {
+			return ErrDuplicateMessage
+		}
+		seen[string(msgs[j])] = true
+
+		roMsg, err := bls.HashToG2(msgs[j], keys[j].dst)
+		if err != nil {
+			return err
+		}
+		P = append(P, keys[j].pk)
+		Q = append(Q, roMsg)
+	}
+
+	var g1Inv bls.G1Affine
+	g1Inv.Neg(&keys[0].g1)
+	P = append(P, g1Inv)
+	Q = append(Q, agg)
+	if !millerCheck(P, Q) {
+		return ErrCombineFailed
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package tss
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+// Combined signatures of the committee on k distinct messages
+func signMany(m *BLS, k int) ([]Message, []bls.G2Affine) {
+	msgs := make([]Message, k)
+	sigmas := make([]bls.G2Affine, k)
+	signers := GetRange(0, m.t+1)
+	for j := range msgs {
+		msgs[j] = []byte(fmt.Sprintf("block %d", j))
+		roMsg, _ := m.hashMsg(msgs[j])
+		partials := make([]bls.G2Jac, len(signers))
+		for i := range signers {
+			partials[i], _ = m.psign(msgs[j], m.pp.signers[i])
+		}
+		sigma := m.verifyCombine(roMsg, signers, partials)
+		sigmas[j].FromJacobian(&sigma)
+	}
+	return msgs, sigmas
+}
+
+func TestAggregateVerify(t *testing.T) {
+	n := 1 << 3
+	crs := GenBLSCRS(n)
+	m1 := NewBLS(n, 3, crs)
+	m2 := NewBLS(n, 3, crs)
+	m3 := NewBLS(n, 3, GenBLSCRSStandard(n))
+
+	var items []SignedMessage
+	for _, m := range []*BLS{&m1, &m2, &m3} {
+		msgs, sigmas := signMany(m, 3)
+		for j := range msgs {
+			items = append(items, SignedMessage{Key: m.groupKey(), Msg: msgs[j], Sigma: sigmas[j]})
+		}
+	}
+	assert.True(t, VerifyBatch(items))
+
+	// Two invalid signatures whose errors cancel in a plain sum
+	swapped := append([]SignedMessage{}, items...)
+	swapped[0].Sigma, swapped[1].Sigma = items[1].Sigma, items[0].Sigma
+	assert.False(t, VerifyBatch(swapped))
+
+	// Aggregation over the keys sharing a generator: m1 on blocks 0-2 and m2
+	// on block 3
+	msgs2, sigmas2 := signMany(&m2, 4)
+	keys := []GroupKey{items[0].Key, items[1].Key, items[2].Key, m2.groupKey()}
+	msgs := []Message{items[0].Msg, items[1].Msg, items[2].Msg, msgs2[3]}
+	sigmas := []bls.G2Affine{items[0].Sigma, items[1].Sigma, items[2].Sigma, sigmas2[3]}
+	agg := AggregateSignatures(sigmas)
+	assert.NoError(t, VerifyAggregate(keys, msgs, agg))
+	assert.ErrorIs(t, VerifyAggregate(keys[1:], msgs[1:], agg), ErrCombineFailed)
+
+	// The same messages under both keys are refused, even though the
+	// aggregate is correct
+	both := AggregateSignatures([]bls.G2Affine{items[0].Sigma, items[3].Sigma})
+	assert.ErrorIs(t, VerifyAggregate([]GroupKey{items[0].Key, items[3].Key}, []Message{items[0].Msg, items[3].Msg}, both), ErrDuplicateMessage)
+
+	// Same key and message twice is refused
+	assert.ErrorIs(t, VerifyAggregate(append(keys, keys[0]), append(msgs, msgs[0]), agg), ErrDuplicateMessage)
+
+	assert.ErrorIs(t, VerifyAggregate(append(keys, items[6].Key), append(msgs, []byte("block 4")), agg), ErrMixedGenerators)
+	assert.ErrorIs(t, VerifyAggregate(keys, msgs, bls.G2Affine{}), ErrInvalidPoint)
+}
+
+// A rogue key cancels the honest one when both sign the same message
+func TestAggregateRogueKey(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, 3, GenBLSCRS(n))
+	msg := Message("transfer all funds")
+	roMsg, _ := m.hashMsg(msg)
+
+	var x fr.Element
+	x.SetRandom()
+	xInt := x.BigInt(&big.Int{})
+	var rogue bls.G1Affine
+	rogue.ScalarMultiplication(&m.crs.g1a, xInt)
+	rogue.Sub(&rogue, &m.pp.pk)
+	var forged bls.G2Affine
+	forged.ScalarMultiplication(&roMsg, xInt)
+
+	honest := m.groupKey()
+	keys := []GroupKey{honest, {pk: rogue, g1: honest.g1, dst: honest.dst}}
+	assert.ErrorIs(t, VerifyAggregate(keys, []Message{msg, msg}, forged), ErrDuplicateMessage)
+}
+
+func TestAggregateVerifyABLS(t *testing.T) {
+	n := 1 << 3
+	m := NewABLS(n, 3, GenABLSCRS(n))
+	signers := GetRange(0, 4)
+
+	var items []SignedMessage
+	for j := 0; j < 3; j++ {
+		msg := []byte(fmt.Sprintf("block %d", j))
+		ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+		partials := make([]bls.G2Jac, len(signers))
+		pfs := make([]SigmaPf, len(signers))
+		for i := range signers {
+			partials[i], pfs[i], _ = m.pSign(msg, m.pp.signers[i])
+		}
+		sigma := m.verifyCombine(ro0Msg, ro1Msg, signers, partials, pfs)
+		items = append(items, SignedMessage{Key: m.groupKey(), Msg: msg, Sigma: *new(bls.G2Affine).FromJacobian(&sigma)})
+	}
+	assert.True(t, VerifyBatch(items))
+	items[2].Msg = []byte("other")
+	assert.False(t, VerifyBatch(items))
+}
+
+func BenchmarkAggregateVerify(b *testing.B) {
+	n := 1 << 4
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+
+	for _, k := range []int{16, 64} {
+		msgs, sigmas := signMany(&m, k)
+		items := make([]SignedMessage, k)
+		keys := make([]GroupKey, k)
+		for j := range items {
+			keys[j] = m.groupKey()
+			items[j] = SignedMessage{Key: keys[j], Msg: msgs[j], Sigma: sigmas[j]}
+		}
+		agg := AggregateSignatures(sigmas)
+
+		b.Run(fmt.Sprintf("%d-gverify", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range msgs {
+					roMsg, _ := m.hashMsg(msgs[j])
+					m.gverify(roMsg, *new(bls.G2Jac).FromAffine(&sigmas[j]))
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%d-batch", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VerifyBatch(items)
+			}
+		})
+
+		b.Run(fmt.Sprintf("%d-aggregate", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VerifyAggregate(keys, msgs, agg)
+			}
+		})
+	}
+}
@@ -0,0 +1,405 @@
+package tss
+
+import (
+	"sort"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+/*
+* Batch verification of partial signatures. A batch is checked with a random
+* linear combination of the individual verification equations; if it fails,
+* the bad partials are found by bisection and replaced with further ones until
+* t+1 partials pass.
+*
+* ABLS proofs are batched in commitment form. A SigmaPf carries the challenge,
+* so the verifier has to rebuild each commitment before it can hash, which
+* leaves nothing to combine across signers.
+ */
+
+// Positions in idx that fail check, assuming small batches of good items pass
+func bisect(idx []int, check func([]int) bool) []int {
+	if len(idx) == 0 || check(idx) {
+		return nil
+	}
+	return bisectFailing(idx, check)
+}
+
+// idx is known to fail; a good left half means the bad items are on the right
+func bisectFailing(idx []int, check func([]int) bool) []int {
+	if len(idx) == 1 {
+		return []int{idx[0]}
+	}
+	mid := len(idx) / 2
+	left, right := idx[:mid], idx[mid:]
+	if check(left) {
+		return bisectFailing(right, check)
+	}
+	bad := bisectFailing(left, check)
+	return append(bad, bisect(right, check)...)
+}
+
+// Picks the first need positions of signers that pass check, verifying them
+// in batches; also returns the positions rejected on the way. Like
+// selectPartials, an index is only batched if it is in range and has not
+// been picked yet, so check never sees a repeated or unknown signer.
+func batchSelect(n, need int, signers []int, check func([]int) bool) ([]int, []Rejection) {
+	var good []int
+	var rejected []Rejection
+	seen := make(map[int]bool, need)
+	for next := 0; len(good) < need && next < len(signers); {
+		var window []int
+		pending := make(map[int]bool)
+		for ; next < len(signers) && len(good)+len(window) < need; next++ {
+			idx := signers[next]
+			if pending[idx] {
+				// Decided once the pending partial of idx is checked
+				break
+			}
+			reason := RejectReason(0)
+			switch {
+			case idx < 0 || idx >= n:
+				reason = RejectUnknownIndex
+			case seen[idx]:
+				reason = RejectDuplicate
+			}
+			if reason != 0 {
+				rejected = append(rejected, Rejection{Signer: idx, Position: next, Reason: reason})
+				continue
+			}
+			pending[idx] = true
+			window = append(window, next)
+		}
+
+		bad := bisect(window, check)
+		for _, pos := range window {
+			if len(bad) > 0 && bad[0] == pos {
+				bad = bad[1:]
+				rejected = append(rejected, Rejection{Signer: signers[pos], Position: pos, Reason: RejectBadProof})
+				continue
+			}
+			seen[signers[pos]] = true
+			good = append(good, pos)
+		}
+	}
+	sort.Slice(rejected, func(i, j int) bool { return rejected[i].Position < rejected[j].Position })
+	return good, rejected
+}
+
+/**************************
+	ADAPTIVE BLS
+***************************/
+
+// SigmaPf in commitment form: x = g1^hs h1^hr v1^hu, y = H0^hs H1^hr and the
+// responses. The challenge is recomputed from the commitments, so that many
+// proofs can be checked in one MSM per group.
+type SigmaCmtPf struct {
+	x  bls.G1Affine
+	y  bls.G2Affine
+	zs fr.Element
+	zr fr.Element
+	zu fr.Element
+}
+
+func (b *ABLS) pSignCmt(msg Message, signer ABLSParty) (bls.G2Jac, SigmaCmtPf, error) {
+	if err := checkPolicy(b.policy, msg, signer.index); err != nil {
+		return bls.G2Jac{}, SigmaCmtPf{}, err
+	}
+
+	ro0Msg, ro1Msg, err := b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, SigmaCmtPf{}, err
+	}
+
+	var sigma bls.G2Jac
+	sigma.MultiExp([]bls.G2Affine{ro0Msg, ro1Msg}, []fr.Element{signer.sKey, signer.rKey}, ecc.MultiExpConfig{})
+	pf, _ := b.sigmaProveCmt(nil, ro0Msg, ro1Msg, sigma, signer)
+	return sigma, pf, nil
+}
+
+// Recomputes the challenge; points outside the subgroup would let a torsion
+// component slip through the random combination
+func (b *ABLS) cmtChallenge(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, index int, sigma bls.G2Affine, pf SigmaCmtPf) (fr.Element, bool) {
+	if validG2(&sigma) != nil || !pf.x.IsInSubGroup() || !pf.y.IsInSubGroup() {
+		return fr.Element{}, false
+	}
+	return b.sigmaChallenge(nil, index, ro0Msg, ro1Msg, b.pp.pKeys[index], sigma, pf.x, pf.y), true
+}
+
+// Checks sum_i rho_i (g1^zs_i h1^zr_i v1^zu_i - x_i - c_i pKey_i) = 0 and
+// sum_i rho_i (H0^zs_i H1^zr_i - y_i - c_i sigma_i) = 0 for random rho_i
+func (b *ABLS) sigmaBatchCheck(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, pKeys []bls.G1Affine, sigmas []bls.G2Affine, cs []fr.Element, pfs []SigmaCmtPf) bool {
+	k := len(pfs)
+	bases1 := append(b.getParamsAff(), make([]bls.G1Affine, 2*k)...)
+	scalars1 := make([]fr.Element, 3+2*k)
+	bases2 := append([]bls.G2Affine{ro0Msg, ro1Msg}, make([]bls.G2Affine, 2*k)...)
+	scalars2 := make([]fr.Element, 2+2*k)
+
+	var zs, zr, zu, rho, tmp fr.Element
+	for i := range pfs {
+		rho.SetRandom()
+		zs.Add(&zs, tmp.Mul(&rho, &pfs[i].zs))
+		zr.Add(&zr, tmp.Mul(&rho, &pfs[i].zr))
+		zu.Add(&zu, tmp.Mul(&rho, &pfs[i].zu))
+
+		var negRho, negRhoC fr.Element
+		negRho.Neg(&rho)
+		negRhoC.Mul(&negRho, &cs[i])
+
+		bases1[3+2*i], scalars1[3+2*i] = pfs[i].x, negRho
+		bases1[4+2*i], scalars1[4+2*i] = pKeys[i], negRhoC
+		bases2[2+2*i], scalars2[2+2*i] = pfs[i].y, negRho
+		bases2[3+2*i], scalars2[3+2*i] = sigmas[i], negRhoC
+	}
+	scalars1[0], scalars1[1], scalars1[2] = zs, zr, zu
+	scalars2[0], scalars2[1] = zs, zr
+
+	var r1 bls.G1Jac
+	var r2 bls.G2Jac
+	r1.MultiExp(bases1, scalars1, ecc.MultiExpConfig{})
+	r2.MultiExp(bases2, scalars2, ecc.MultiExpConfig{})
+	return r1.Z.IsZero() && r2.Z.IsZero()
+}
+
+// Batch verifies all partials and returns the positions of the bad ones
+func (b *ABLS) pVerifyBatch(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaCmtPf) []int {
+	check := b.sigmaChecker(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	return bisect(GetRange(0, len(signers)), check)
+}
+
+// Like verifyCombine, but verifies the partials in batches
+func (b *ABLS) verifyCombineBatch(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaCmtPf) bls.G2Jac {
+	check := b.sigmaChecker(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	good, _ := batchSelect(b.n, b.t+1, signers, check)
+
+	vfSigners := make([]int, len(good))
+	vfSigs := make([]bls.G2Affine, len(good))
+	for i, pos := range good {
+		vfSigners[i] = signers[pos]
+		vfSigs[i].FromJacobian(&sigmas[pos])
+	}
+	return b.combine(vfSigners, vfSigs)
+}
+
+// Returns a check over positions of the inputs; per-partial work (challenge,
+// subgroup checks, affine conversion) is done once and cached across bisection
+func (b *ABLS) sigmaChecker(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaCmtPf) func([]int) bool {
+	type prepared struct {
+		done, ok bool
+		pKey     bls.G1Affine
+		sigma    bls.G2Affine
+		c        fr.Element
+	}
+	prep := make([]prepared, len(signers))
+
+	return func(pos []int) bool {
+		pKeys := make([]bls.G1Affine, len(pos))
+		sigmasAf := make([]bls.G2Affine, len(pos))
+		cs := make([]fr.Element, len(pos))
+		batch := make([]SigmaCmtPf, len(pos))
+		for i, p := range pos {
+			pr := &prep[p]
+			if !pr.done {
+				pr.done = true
+				if idx := signers[p]; idx >= 0 && idx < b.n {
+					pr.pKey = b.pp.pKeys[idx]
+					pr.sigma.FromJacobian(&sigmas[p])
+					pr.c, pr.ok = b.cmtChallenge(ro0Msg, ro1Msg, idx, pr.sigma, pfs[p])
+				}
+			}
+			if !pr.ok {
+				return false
+			}
+			pKeys[i], sigmasAf[i], cs[i], batch[i] = pr.pKey, pr.sigma, pr.c, pfs[p]
+		}
+		return b.sigmaBatchCheck(ro0Msg, ro1Msg, pKeys, sigmasAf, cs, batch)
+	}
+}
+
+/**************************
+	BOLDYREVA
+***************************/
+
+// Checks e(sum_i rho_i pKey_i, H(m)) = e(g1, sum_i rho_i sigma_i) for random
+// rho_i: two Miller loops for the whole batch
+func (b *BLS) pairingBatchCheck(roMsg bls.G2Affine, pKeys []bls.G1Affine, sigmas []bls.G2Affine) bool {
+	rhos := make([]fr.Element, len(pKeys))
+	for i := range rhos {
+		rhos[i].SetRandom()
+	}
+
+	var pkAgg bls.G1Affine
+	var sigAgg bls.G2Affine
+	pkAgg.MultiExp(pKeys, rhos, ecc.MultiExpConfig{})
+	sigAgg.MultiExp(sigmas, rhos, ecc.MultiExpConfig{})
+
+	res, _ := bls.PairingCheck([]bls.G1Affine{pkAgg, b.crs.g1InvAf}, []bls.G2Affine{roMsg, sigAgg})
+	return res
+}
+
+// Returns a check over positions of the inputs with the affine conversion and
+// subgroup check of every partial done once
+func (b *BLS) pairingChecker(roMsg bls.G2Affine, signers []int, sigmas []bls.G2Jac) func([]int) bool {
+	type prepared struct {
+		done, ok bool
+		sigma    bls.G2Affine
+	}
+	prep := make([]prepared, len(signers))
+
+	return func(pos []int) bool {
+		pKeys := make([]bls.G1Affine, len(pos))
+		sigmasAf := make([]bls.G2Affine, len(pos))
+		for i, p := range pos {
+			pr := &prep[p]
+			if !pr.done {
+				pr.done = true
+				if idx := signers[p]; idx >= 0 && idx < b.n {
+					pr.sigma.FromJacobian(&sigmas[p])
+					pr.ok = validG2(&pr.sigma) == nil
+				}
+			}
+			if !pr.ok {
+				return false
+			}
+			pKeys[i], sigmasAf[i] = b.pp.pKeys[signers[p]], pr.sigma
+		}
+		return b.pairingBatchCheck(roMsg, pKeys, sigmasAf)
+	}
+}
+
+// Batch verifies all partials on roMsg and returns the positions of the bad ones
+func (b *BLS) pverifyBatch(roMsg bls.G2Affine, signers []int, sigmas []bls.G2Jac) []int {
+	check := b.pairingChecker(roMsg, signers, sigmas)
+	return bisect(GetRange(0, len(signers)), check)
+}
+
+// Like verifyCombine, but verifies the partials in batches
+func (b *BLS) verifyCombineBatch(roMsg bls.G2Affine, signers []int, sigmas []bls.G2Jac) bls.G2Jac {
+	check := b.pairingChecker(roMsg, signers, sigmas)
+	good, _ := batchSelect(b.n, b.t+1, signers, check)
+	return b.combinePositions(good, signers, sigmas)
+}
+
+func (b *BLS) combinePositions(good []int, signers []int, sigmas []bls.G2Jac) bls.G2Jac {
+	vfSigners := make([]int, len(good))
+	vfSigs := make([]bls.G2Affine, len(good))
+	for i, pos := range good {
+		vfSigners[i] = signers[pos]
+		vfSigs[i].FromJacobian(&sigmas[pos])
+	}
+	return b.combine(vfSigners, vfSigs)
+}
+
+// Pf in commitment form: a = g1^r, b = H(m)^r and the response
+type DleqCmtPf struct {
+	a bls.G1Affine
+	b bls.G2Affine
+	z fr.Element
+}
+
+func (b *BLS) pSignDleqCmt(msg Message, signer BLSParty) (bls.G2Jac, DleqCmtPf, error) {
+	return b.pSignDleqCmtCtx(nil, msg, signer)
+}
+
+// Partial signature whose proof is additionally bound to ctx
+func (b *BLS) pSignDleqCmtCtx(ctx []byte, msg Message, signer BLSParty) (bls.G2Jac, DleqCmtPf, error) {
+	if err := checkPolicy(b.policy, msg, signer.index); err != nil {
+		return bls.G2Jac{}, DleqCmtPf{}, err
+	}
+
+	roMsgAf, err := b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, DleqCmtPf{}, err
+	}
+	sigma := b.signPoint(roMsgAf, signer)
+	pf, _ := b.cpProveCmt(ctx, roMsgAf, sigma, signer)
+	return sigma, pf, nil
+}
+
+// Checks sum_i rho_i (g1^z_i - a_i - c_i pKey_i) = 0 and
+// sum_i rho_i (H(m)^z_i - b_i - c_i sigma_i) = 0 for random rho_i
+func (b *BLS) dleqBatchCheck(roMsg bls.G2Affine, pKeys []bls.G1Affine, sigmas []bls.G2Affine, cs []fr.Element, pfs []DleqCmtPf) bool {
+	k := len(pfs)
+	bases1 := append([]bls.G1Affine{b.crs.g1a}, make([]bls.G1Affine, 2*k)...)
+	bases2 := append([]bls.G2Affine{roMsg}, make([]bls.G2Affine, 2*k)...)
+	scalars := make([]fr.Element, 1+2*k)
+
+	var z, rho, tmp fr.Element
+	for i := range pfs {
+		rho.SetRandom()
+		z.Add(&z, tmp.Mul(&rho, &pfs[i].z))
+
+		var negRho, negRhoC fr.Element
+		negRho.Neg(&rho)
+		negRhoC.Mul(&negRho, &cs[i])
+
+		bases1[1+2*i], bases2[1+2*i], scalars[1+2*i] = pfs[i].a, pfs[i].b, negRho
+		bases1[2+2*i], bases2[2+2*i], scalars[2+2*i] = pKeys[i], sigmas[i], negRhoC
+	}
+	scalars[0] = z
+
+	var r1 bls.G1Jac
+	var r2 bls.G2Jac
+	r1.MultiExp(bases1, scalars, ecc.MultiExpConfig{})
+	r2.MultiExp(bases2, scalars, ecc.MultiExpConfig{})
+	return r1.Z.IsZero() && r2.Z.IsZero()
+}
+
+// Returns a check over positions of the inputs, caching the challenge of every
+// proof. ctxs holds the context each proof is bound to, or is nil when none is.
+func (b *BLS) dleqChecker(roMsg bls.G2Affine, ctxs [][]byte, signers []int, sigmas []bls.G2Jac, pfs []DleqCmtPf) func([]int) bool {
+	type prepared struct {
+		done, ok bool
+		pKey     bls.G1Affine
+		sigma    bls.G2Affine
+		c        fr.Element
+	}
+	prep := make([]prepared, len(signers))
+
+	return func(pos []int) bool {
+		pKeys := make([]bls.G1Affine, len(pos))
+		sigmasAf := make([]bls.G2Affine, len(pos))
+		cs := make([]fr.Element, len(pos))
+		batch := make([]DleqCmtPf, len(pos))
+		for i, p := range pos {
+			pr := &prep[p]
+			if !pr.done {
+				pr.done = true
+				pf := pfs[p]
+				if idx := signers[p]; idx >= 0 && idx < b.n {
+					pr.pKey = b.pp.pKeys[idx]
+					pr.sigma.FromJacobian(&sigmas[p])
+					// Points outside the subgroup would let a torsion
+					// component slip through the random combination
+					if validG2(&pr.sigma) == nil && pf.a.IsInSubGroup() && pf.b.IsInSubGroup() {
+						var ctx []byte
+						if ctxs != nil {
+							ctx = ctxs[p]
+						}
+						pr.c = b.dleqChallenge(ctx, idx, roMsg, pr.pKey, pr.sigma, pf.a, pf.b)
+						pr.ok = true
+					}
+				}
+			}
+			if !pr.ok {
+				return false
+			}
+			pKeys[i], sigmasAf[i], cs[i], batch[i] = pr.pKey, pr.sigma, pr.c, pfs[p]
+		}
+		return b.dleqBatchCheck(roMsg, pKeys, sigmasAf, cs, batch)
+	}
+}
+
+// Batch verifies all DLEQ partials on roMsg and returns the positions of the bad ones
+func (b *BLS) pVerifyDleqBatch(roMsg bls.G2Affine, ctxs [][]byte, signers []int, sigmas []bls.G2Jac, pfs []DleqCmtPf) []int {
+	return bisect(GetRange(0, len(signers)), b.dleqChecker(roMsg, ctxs, signers, sigmas, pfs))
+}
+
+// Like verifyCombineDleq, but verifies the proofs in batches
+func (b *BLS) verifyCombineDleqBatch(roMsg bls.G2Affine, ctxs [][]byte, signers []int, sigmas []bls.G2Jac, pfs []DleqCmtPf) bls.G2Jac {
+	check := b.dleqChecker(roMsg, ctxs, signers, sigmas, pfs)
+	good, _ := batchSelect(b.n, b.t+1, signers, check)
+	return b.combinePositions(good, signers, sigmas)
+}
@@ -0,0 +1,262 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBisect(t *testing.T) {
+	bad := map[int]bool{2: true, 3: true, 9: true}
+	check := func(pos []int) bool {
+		for _, p := range pos {
+			if bad[p] {
+				return false
+			}
+		}
+		return true
+	}
+	assert.Equal(t, []int{2, 3, 9}, bisect(GetRange(0, 12), check))
+	assert.Nil(t, bisect(GetRange(4, 9), check))
+	positions := func(rejected []Rejection) []int {
+		var pos []int
+		for _, rej := range rejected {
+			pos = append(pos, rej.Position)
+		}
+		return pos
+	}
+	good, rejected := batchSelect(12, 5, GetRange(0, 12), check)
+	assert.Equal(t, []int{0, 1, 4, 5, 6}, good)
+	assert.Equal(t, []int{2, 3}, positions(rejected))
+	good, rejected = batchSelect(12, 10, GetRange(0, 12), check)
+	assert.Equal(t, []int{0, 1, 4, 5, 6, 7, 8, 10, 11}, good)
+	assert.Equal(t, []int{2, 3, 9}, positions(rejected))
+
+	// Repeated and unknown indices never reach check; a repeat of a bad
+	// partial is still tried
+	signers := []int{0, 0, 12, 2, 1, 2, 5, 1, 6}
+	good, rejected = batchSelect(12, 5, signers, func(pos []int) bool {
+		for _, p := range pos {
+			if p == 3 {
+				return false
+			}
+		}
+		return true
+	})
+	assert.Equal(t, []int{0, 4, 5, 6, 8}, good)
+	assert.Equal(t, []Rejection{
+		{Signer: 0, Position: 1, Reason: RejectDuplicate},
+		{Signer: 12, Position: 2, Reason: RejectUnknownIndex},
+		{Signer: 2, Position: 3, Reason: RejectBadProof},
+		{Signer: 1, Position: 7, Reason: RejectDuplicate},
+	}, rejected)
+}
+
+// The first t+1 partials repeat a signer; the repeat is dropped and the next
+// partial takes its place
+func duplicateFirst(ths int) []int {
+	return append([]int{0}, GetRange(0, ths+1)...)
+}
+
+func TestABLSBatch(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaCmtPf
+	for i := 0; i < n; i++ {
+		sigma, pf, err := m.pSignCmt(msg, m.pp.signers[i])
+		assert.NoError(t, err)
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	assert.Nil(t, m.pVerifyBatch(ro0Msg, ro1Msg, signers, sigmas, pfs))
+
+	dup := duplicateFirst(ths)
+	msig := m.verifyCombineBatch(ro0Msg, ro1Msg, dup, pick(sigmas, dup), pick(pfs, dup))
+	assert.True(t, m.gverify(ro0Msg, msig))
+
+	// The commitment form proves the same statement as SigmaPf
+	pf, c := m.sigmaProveCmt(nil, ro0Msg, ro1Msg, sigmas[0], m.pp.signers[0])
+	assert.True(t, m.pVerify(ro0Msg, ro1Msg, sigmas[0], 0, SigmaPf{c, pf.zs, pf.zr, pf.zu}))
+
+	// Wrong signature, wrong proof, unknown index and a torsion point
+	sigmas[1] = sigmas[2]
+	pfs[4].zu = pfs[5].zu
+	signers[7] = n
+	torsion := nonSubgroupG2()
+	sigmas[9].AddAssign(new(bls.G2Jac).FromAffine(&torsion))
+	assert.Equal(t, []int{1, 4, 7, 9}, m.pVerifyBatch(ro0Msg, ro1Msg, signers, sigmas, pfs))
+
+	msig = m.verifyCombineBatch(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	assert.True(t, m.gverify(ro0Msg, msig))
+
+	// Too many bad partials
+	msig = m.verifyCombineBatch(ro0Msg, ro1Msg, signers[:ths+1], sigmas[:ths+1], pfs[:ths+1])
+	assert.False(t, m.gverify(ro0Msg, msig))
+
+}
+
+func TestBLSBatch(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	for i := 0; i < n; i++ {
+		sigma, _ := m.psign(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+	}
+	assert.Nil(t, m.pverifyBatch(roMsg, signers, sigmas))
+
+	dup := duplicateFirst(ths)
+	msig := m.verifyCombineBatch(roMsg, dup, pick(sigmas, dup))
+	assert.True(t, m.gverify(roMsg, msig))
+
+	// Swapped signatures, unknown index and a torsion point
+	sigmas[0], sigmas[3] = sigmas[3], sigmas[0]
+	signers[8] = -1
+	torsion := nonSubgroupG2()
+	sigmas[15].AddAssign(new(bls.G2Jac).FromAffine(&torsion))
+	assert.Equal(t, []int{0, 3, 8, 15}, m.pverifyBatch(roMsg, signers, sigmas))
+
+	msig = m.verifyCombineBatch(roMsg, signers, sigmas)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	msig = m.verifyCombineBatch(roMsg, signers[:ths+1], sigmas[:ths+1])
+	assert.False(t, m.gverify(roMsg, msig))
+
+}
+
+func TestBLSDleqBatch(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []DleqCmtPf
+	for i := 0; i < n; i++ {
+		sigma, pf, _ := m.pSignDleqCmt(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	assert.Nil(t, m.pVerifyDleqBatch(roMsg, nil, signers, sigmas, pfs))
+
+	dup := duplicateFirst(ths)
+	msig := m.verifyCombineDleqBatch(roMsg, nil, dup, pick(sigmas, dup), pick(pfs, dup))
+	assert.True(t, m.gverify(roMsg, msig))
+
+	// The proof checks out on its own as well
+	var sigmaAf bls.G2Affine
+	sigmaAf.FromJacobian(&sigmas[0])
+	c := m.dleqChallenge(nil, 0, roMsg, m.pp.pKeys[0], sigmaAf, pfs[0].a, pfs[0].b)
+	assert.True(t, m.pVerifyDleq(roMsg, sigmas[0], 0, Pf{c: c, z: pfs[0].z}))
+
+	// Bad response, commitments that do not match the challenge, wrong
+	// signature, unknown signer
+	pfs[5].z = pfs[6].z
+	pfs[9].a = pfs[10].a
+	sigmas[11] = sigmas[12]
+	signers[13] = n
+	assert.Equal(t, []int{5, 9, 11, 13}, m.pVerifyDleqBatch(roMsg, nil, signers, sigmas, pfs))
+
+	msig = m.verifyCombineDleqBatch(roMsg, nil, signers, sigmas, pfs)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	msig = m.verifyCombineDleqBatch(roMsg, nil, signers[:ths+1], sigmas[:ths+1], pfs[:ths+1])
+	assert.False(t, m.gverify(roMsg, msig))
+
+}
+
+func TestBLSDleqBatchToggle(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	m.SetBatchDleq(true)
+	signers := GetRange(0, n)
+	sigmas := make([]bls.G2Jac, n)
+	pfs := make([]Pf, n)
+	for i := range signers {
+		sigmas[i], pfs[i], _ = m.pSignDleq(msg, m.pp.signers[i])
+		assert.NotNil(t, pfs[i].cmt)
+		// The attached commitments do not change the (c, z) proof
+		assert.True(t, m.pVerifyDleq(roMsg, sigmas[i], i, pfs[i]))
+	}
+	msig := m.verifyCombineDleq(roMsg, signers, sigmas, pfs)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	// Bad proofs and repeated signers are left out of the batch
+	bad := append([]Pf{}, pfs...)
+	bad[1] = pfs[2]
+	dup := append([]int{0}, signers...)
+	msig = m.verifyCombineDleq(roMsg, dup, append([]bls.G2Jac{sigmas[0]}, sigmas...), append([]Pf{pfs[0]}, bad...))
+	assert.True(t, m.gverify(roMsg, msig))
+	msig = m.verifyCombineDleq(roMsg, signers[:ths+1], sigmas[:ths+1], bad[:ths+1])
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+
+	// Proofs without commitments are checked one by one
+	plain := append([]Pf{}, pfs...)
+	plain[0].cmt = nil
+	msig = m.verifyCombineDleq(roMsg, signers, sigmas, plain)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	m.SetBatchDleq(false)
+	_, pf, _ := m.pSignDleq(msg, m.pp.signers[0])
+	assert.Nil(t, pf.cmt)
+}
+
+// Proofs bound to a session take the batched path with their contexts
+func TestBLSDleqBatchContext(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+	session := SessionID{1}
+
+	signers := GetRange(0, n)
+	ctxs := make([][]byte, n)
+	sigmas := make([]bls.G2Jac, n)
+	pfs := make([]DleqCmtPf, n)
+	for i := range signers {
+		env := newEnvelope(session, msg, i, SchemeBoldyrevaI)
+		ctxs[i] = env.context()
+		sigmas[i], pfs[i], _ = m.pSignDleqCmtCtx(ctxs[i], msg, m.pp.signers[i])
+	}
+	assert.Nil(t, m.pVerifyDleqBatch(roMsg, ctxs, signers, sigmas, pfs))
+	msig := m.verifyCombineDleqBatch(roMsg, ctxs, signers, sigmas, pfs)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	assert.Equal(t, signers, m.pVerifyDleqBatch(roMsg, nil, signers, sigmas, pfs))
+	ctxs[2], ctxs[4] = ctxs[4], ctxs[2]
+	assert.Equal(t, []int{2, 4}, m.pVerifyDleqBatch(roMsg, ctxs, signers, sigmas, pfs))
+}
+
+func pick[T any](items []T, pos []int) []T {
+	out := make([]T, len(pos))
+	for i, p := range pos {
+		out[i] = items[p]
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package tss
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Beacon schemes, named as in drand's chain info
+type BeaconScheme string
+
+const (
+	SchemeChained   BeaconScheme = "pedersen-bls-chained"
+	SchemeUnchained BeaconScheme = "pedersen-bls-unchained"
+)
+
+var (
+	ErrUnknownScheme = errors.New("unknown beacon scheme")
+	ErrWrongRound    = errors.New("beacon round is not the next round")
+	ErrBadRandomness = errors.New("beacon randomness does not match its signature")
+)
+
+// HexBytes is encoded as a lowercase hex string without prefix, as drand does
+type HexBytes []byte
+
+func (h HexBytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+func (h *HexBytes) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+// Beacon is a round of the randomness beacon in drand's JSON format
+type Beacon struct {
+	Round             uint64   `json:"round"`
+	Randomness        HexBytes `json:"randomness"`
+	Signature         HexBytes `json:"signature"`
+	PreviousSignature HexBytes `json:"previous_signature,omitempty"`
+}
+
+// RoundMessage is the digest signed at a round: sha256(prevSig || round) for
+// the chained scheme and sha256(round) for the unchained one, with the round
+// as a big-endian uint64.
+func RoundMessage(scheme BeaconScheme, round uint64, prevSig []byte) (Message, error) {
+	h := sha256.New()
+	switch scheme {
+	case SchemeChained:
+		h.Write(prevSig)
+	case SchemeUnchained:
+	default:
+		return nil, ErrUnknownScheme
+	}
+	h.Write(binary.BigEndian.AppendUint64(nil, round))
+	return h.Sum(nil), nil
+}
+
+// BeaconChain drives the committee from one round to the next. Its BLS
+// instance must use GenBLSCRSStandard for the output to verify with drand
+// clients.
+type BeaconChain struct {
+	b      *BLS
+	scheme BeaconScheme
+	round  uint64
+	prev   []byte
+}
+
+// NewBeaconChain starts a chain at round 0, whose signature is the genesis seed
+func NewBeaconChain(b *BLS, scheme BeaconScheme, genesisSeed []byte) (*BeaconChain, error) {
+	if scheme != SchemeChained && scheme != SchemeUnchained {
+		return nil, ErrUnknownScheme
+	}
+	return &BeaconChain{b: b, scheme: scheme, prev: genesisSeed}, nil
+}
+
+// nextMessage returns the next round and the message signed in it
+func (c *BeaconChain) nextMessage() (uint64, Message, error) {
+	round := c.round + 1
+	msg, err := RoundMessage(c.scheme, round, c.prev)
+	return round, msg, err
+}
+
+// partial is a party's partial signature for the next round
+func (c *BeaconChain) partial(signer BLSParty) (uint64, bls.G2Jac, error) {
+	round, msg, err := c.nextMessage()
+	if err != nil {
+		return 0, bls.G2Jac{}, err
+	}
+	sigma, err := c.b.psign(msg, signer)
+	return round, sigma, err
+}
+
+// advance combines the partials for round and moves the chain forward
+func (c *BeaconChain) advance(round uint64, signers []int, sigmas []bls.G2Jac) (Beacon, error) {
+	next, msg, err := c.nextMessage()
+	if err != nil {
+		return Beacon{}, err
+	}
+	if round != next {
+		return Beacon{}, ErrWrongRound
+	}
+	roMsg, err := c.b.hashMsg(msg)
+	if err != nil {
+		return Beacon{}, err
+	}
+
+	sigma := c.b.verifyCombine(roMsg, signers, sigmas)
+	if !c.b.gverify(roMsg, sigma) {
+		return Beacon{}, ErrCombineFailed
+	}
+
+	sigmaAff := *new(bls.G2Affine).FromJacobian(&sigma)
+	sigBytes := sigmaAff.Bytes()
+	randomness := sha256.Sum256(sigBytes[:])
+	beacon := Beacon{
+		Round:      round,
+		Randomness: randomness[:],
+		Signature:  sigBytes[:],
+	}
+	if c.scheme == SchemeChained {
+		beacon.PreviousSignature = c.prev
+	}
+
+	c.round = round
+	c.prev = beacon.Signature
+	return beacon, nil
+}
+
+// VerifyBeacon checks a beacon the way drand clients do, against the group
+// key pk over the standard generator. For the chained scheme the previous
+// signature is taken from the beacon.
+func VerifyBeacon(scheme BeaconScheme, pk bls.G1Affine, beacon Beacon) error {
+	msg, err := RoundMessage(scheme, beacon.Round, beacon.PreviousSignature)
+	if err != nil {
+		return err
+	}
+
+	var sigma bls.G2Affine
+	if err := sigma.Unmarshal(beacon.Signature); err != nil {
+		return fmt.Errorf("invalid beacon signature: %w", err)
+	}
+	randomness := sha256.Sum256(beacon.Signature)
+	if !bytes.Equal(randomness[:], beacon.Randomness) {
+		return ErrBadRandomness
+	}
+
+	_, _, g1, _ := bls.Generators()
+	key := VRFPublicKey{pk: pk, g1: g1, dst: []byte(blsSigDST)}
+	if !verifyWithKey(key, msg, sigma) {
+		return ErrCombineFailed
+	}
+	return nil
+}
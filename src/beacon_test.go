@@ -0,0 +1,207 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func runBeacon(t *testing.T, m *BLS, scheme BeaconScheme, rounds int) []Beacon {
+	chain, err := NewBeaconChain(m, scheme, []byte("genesis seed"))
+	assert.NoError(t, err)
+
+	var beacons []Beacon
+	for r := 0; r < rounds; r++ {
+		var round uint64
+		var signers []int
+		var sigmas []bls.G2Jac
+		// Rotate the quorum from round to round
+		for i := 0; i <= m.t; i++ {
+			idx := (i + r) % m.n
+			var sigma bls.G2Jac
+			round, sigma, err = chain.partial(m.pp.signers[idx])
+			assert.NoError(t, err)
+			signers = append(signers, idx)
+			sigmas = append(sigmas, sigma)
+		}
+		beacon, err := chain.advance(round, signers, sigmas)
+		assert.NoError(t, err)
+		beacons = append(beacons, beacon)
+	}
+	return beacons
+}
+
+func TestBeaconChained(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRSStandard(n))
+	beacons := runBeacon(t, &m, SchemeChained, 3)
+
+	for i, beacon := range beacons {
+		assert.Equal(t, uint64(i+1), beacon.Round)
+		if i > 0 {
+			assert.Equal(t, beacons[i-1].Signature, beacon.PreviousSignature)
+		}
+
+		// Beacons survive the drand JSON encoding
+		enc, err := json.Marshal(beacon)
+		assert.NoError(t, err)
+		var dec Beacon
+		assert.NoError(t, json.Unmarshal(enc, &dec))
+		assert.NoError(t, VerifyBeacon(SchemeChained, m.pp.pk, dec))
+
+		var fields map[string]any
+		assert.NoError(t, json.Unmarshal(enc, &fields))
+		assert.Equal(t, hex.EncodeToString(beacon.Signature), fields["signature"])
+		assert.Contains(t, fields, "previous_signature")
+	}
+	assert.Equal(t, HexBytes("genesis seed"), beacons[0].PreviousSignature)
+
+	// Breaking the chain link invalidates the beacon
+	broken := beacons[2]
+	broken.PreviousSignature = beacons[0].Signature
+	assert.ErrorIs(t, VerifyBeacon(SchemeChained, m.pp.pk, broken), ErrCombineFailed)
+
+	broken = beacons[2]
+	broken.Randomness = beacons[1].Randomness
+	assert.ErrorIs(t, VerifyBeacon(SchemeChained, m.pp.pk, broken), ErrBadRandomness)
+}
+
+func TestBeaconUnchained(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRSStandard(n))
+	beacons := runBeacon(t, &m, SchemeUnchained, 2)
+
+	for _, beacon := range beacons {
+		assert.Nil(t, beacon.PreviousSignature)
+		assert.NoError(t, VerifyBeacon(SchemeUnchained, m.pp.pk, beacon))
+
+		enc, err := json.Marshal(beacon)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(enc), "previous_signature")
+	}
+
+	// A round number that is off by one does not verify
+	shifted := beacons[1]
+	shifted.Round++
+	assert.ErrorIs(t, VerifyBeacon(SchemeUnchained, m.pp.pk, shifted), ErrCombineFailed)
+
+	// A committee with a non-standard generator is not drand compatible
+	other := NewBLS(n, n/2, GenBLSCRS(n))
+	assert.Error(t, VerifyBeacon(SchemeUnchained, other.pp.pk, beacons[0]))
+}
+
+func TestBeaconWrongRound(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRSStandard(n))
+	chain, err := NewBeaconChain(&m, SchemeUnchained, nil)
+	assert.NoError(t, err)
+
+	_, err = chain.advance(2, nil, nil)
+	assert.ErrorIs(t, err, ErrWrongRound)
+	_, err = NewBeaconChain(&m, "bls-unchained-on-g1", nil)
+	assert.ErrorIs(t, err, ErrUnknownScheme)
+}
+
+func TestRoundMessage(t *testing.T) {
+	prev := []byte{0xab, 0xcd}
+	round := []byte{0, 0, 0, 0, 0, 0, 0x01, 0x02}
+
+	msg, err := RoundMessage(SchemeChained, 0x0102, prev)
+	assert.NoError(t, err)
+	expected := sha256.Sum256(append(prev, round...))
+	assert.Equal(t, expected[:], []byte(msg))
+
+	msg, err = RoundMessage(SchemeUnchained, 0x0102, prev)
+	assert.NoError(t, err)
+	expected = sha256.Sum256(round)
+	assert.Equal(t, expected[:], []byte(msg))
+}
+
+// Rounds published by drand, see testdata/drand/README.md
+func TestBeaconDrandVectors(t *testing.T) {
+	files, _ := filepath.Glob(filepath.Join("testdata", "drand", "*.json"))
+	if len(files) == 0 {
+		t.Fatal("no drand rounds in testdata/drand")
+	}
+	assert.Contains(t, files, filepath.Join("testdata", "drand", "mainnet.json"))
+
+	_, _, g1, _ := bls.Generators()
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			var vectors struct {
+				Info struct {
+					PublicKey   HexBytes     `json:"public_key"`
+					Period      uint32       `json:"period"`
+					GenesisTime int64        `json:"genesis_time"`
+					Hash        HexBytes     `json:"hash"`
+					GroupHash   HexBytes     `json:"groupHash"`
+					SchemeID    BeaconScheme `json:"schemeID"`
+				} `json:"info"`
+				Beacons []Beacon `json:"beacons"`
+			}
+			assert.NoError(t, json.Unmarshal(data, &vectors))
+			info := vectors.Info
+			scheme := info.SchemeID
+			if scheme != SchemeChained && scheme != SchemeUnchained {
+				t.Skipf("scheme %s has keys in G2", scheme)
+			}
+
+			// chain hash of the default beacon: period || genesis || pk || group hash
+			if len(info.Hash) > 0 {
+				var buf []byte
+				buf = binary.BigEndian.AppendUint32(buf, info.Period)
+				buf = binary.BigEndian.AppendUint64(buf, uint64(info.GenesisTime))
+				buf = append(buf, info.PublicKey...)
+				buf = append(buf, info.GroupHash...)
+				hash := sha256.Sum256(buf)
+				assert.Equal(t, []byte(info.Hash), hash[:])
+			}
+
+			var pk bls.G1Affine
+			_, err = pk.SetBytes(info.PublicKey)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, vectors.Beacons)
+			for _, beacon := range vectors.Beacons {
+				assert.NoError(t, VerifyBeacon(scheme, pk, beacon), "round %d", beacon.Round)
+
+				// recompute message, DST and randomness independently of VerifyBeacon
+				var layout []byte
+				if scheme == SchemeChained {
+					layout = append(layout, beacon.PreviousSignature...)
+				}
+				layout = binary.BigEndian.AppendUint64(layout, beacon.Round)
+				msg := sha256.Sum256(layout)
+				h, err := bls.HashToG2(msg[:], []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"))
+				assert.NoError(t, err)
+				var sigma bls.G2Affine
+				_, err = sigma.SetBytes(beacon.Signature)
+				assert.NoError(t, err)
+				var g1Neg bls.G1Affine
+				g1Neg.Neg(&g1)
+				ok, err := bls.PairingCheck([]bls.G1Affine{pk, g1Neg}, []bls.G2Affine{h, sigma})
+				assert.NoError(t, err)
+				assert.True(t, ok, "round %d", beacon.Round)
+				randomness := sha256.Sum256(beacon.Signature)
+				assert.Equal(t, randomness[:], []byte(beacon.Randomness))
+
+				shifted := beacon
+				shifted.Round++
+				assert.ErrorIs(t, VerifyBeacon(scheme, pk, shifted), ErrCombineFailed)
+				if scheme == SchemeChained {
+					relinked := beacon
+					relinked.PreviousSignature = beacon.Signature
+					assert.ErrorIs(t, VerifyBeacon(scheme, pk, relinked), ErrCombineFailed)
+				}
+			}
+		})
+	}
+}
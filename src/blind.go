@@ -0,0 +1,107 @@
+package tss
+
+import (
+	"math/big"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+/*
+* Blind signing uses multiplicative blinding. The user sends H(m)^rho for a
+* random rho, so the signers see a uniformly random element of G2. Signing is
+* a group homomorphism, hence the combined blinded signature is H(m)^{s rho}
+* and raising it to 1/rho gives the ordinary threshold signature H(m)^s.
+*
+* For ABLS only H0(m) is blinded. The signers derive the second point from
+* the blinded one, B1 = H(B0), instead of taking it from the requester, who
+* could otherwise send correlated points such as B1 = B0^k and learn
+* s_i + k r_i. Since r(0) = 0 the second point drops out of the combined
+* signature, which is B0^s = H0(m)^{s rho} whatever B1 is.
+ */
+
+// Blinds the hashed message points with a fresh non-zero factor
+func blindPoints(points ...bls.G2Affine) ([]bls.G2Affine, fr.Element) {
+	var rho fr.Element
+	for rho.IsZero() {
+		rho.SetRandom()
+	}
+	blinded := make([]bls.G2Affine, len(points))
+	rhoInt := rho.BigInt(&big.Int{})
+	for i := range points {
+		blinded[i].ScalarMultiplication(&points[i], rhoInt)
+	}
+	return blinded, rho
+}
+
+func unblind(sigma bls.G2Jac, rho fr.Element) bls.G2Jac {
+	var rhoInv fr.Element
+	rhoInv.Inverse(&rho)
+	return *new(bls.G2Jac).ScalarMultiplication(&sigma, rhoInv.BigInt(&big.Int{}))
+}
+
+// Signers only accept blinded inputs that are proper G2 elements; signing an
+// element outside the subgroup could leak the key share modulo the cofactor.
+// The policy sees the compressed encoding of the blinded points.
+func checkBlinded(p SignPolicy, signer int, points ...bls.G2Affine) error {
+	var enc []byte
+	for i := range points {
+		if points[i].IsInfinity() || !points[i].IsInSubGroup() {
+			return ErrInvalidPoint
+		}
+		pBytes := points[i].Bytes()
+		enc = append(enc, pBytes[:]...)
+	}
+	return checkPolicy(p, enc, signer)
+}
+
+func (b *BLS) psignBlind(blinded bls.G2Affine, signer BLSParty) (bls.G2Jac, error) {
+	if err := checkBlinded(b.policy, signer.index, blinded); err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.signPoint(blinded, signer), nil
+}
+
+func (b *BLS) pSignDleqBlind(blinded bls.G2Affine, signer BLSParty) (bls.G2Jac, Pf, error) {
+	if err := checkBlinded(b.policy, signer.index, blinded); err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	sigma, pf := b.signPointDleq(nil, blinded, signer)
+	return sigma, pf, nil
+}
+
+// Verifies the blinded partials against the blinded point, combines them and unblinds the result
+func (b *BLS) verifyCombineBlind(blinded bls.G2Affine, rho fr.Element, signers []int, sigmas []bls.G2Jac) bls.G2Jac {
+	return unblind(b.verifyCombine(blinded, signers, sigmas), rho)
+}
+
+func (b *BLS) verifyCombineDleqBlind(blinded bls.G2Affine, rho fr.Element, signers []int, sigmas []bls.G2Jac, pfs []Pf) bls.G2Jac {
+	return unblind(b.verifyCombineDleq(blinded, signers, sigmas, pfs), rho)
+}
+
+// Derives the second point B1 = H(B0) that blinded partials are signed and
+// verified under
+func (b *ABLS) blindedPoint1(blinded0 bls.G2Affine) (bls.G2Affine, error) {
+	bBytes := blinded0.Bytes()
+	return bls.HashToG2(bBytes[:], []byte("ABLS_BLIND_DST1"))
+}
+
+func (b *ABLS) pSignBlind(blinded0 bls.G2Affine, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	if err := checkBlinded(b.policy, signer.index, blinded0); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	blinded1, err := b.blindedPoint1(blinded0)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	sigma, pf := b.signPoints(nil, blinded0, blinded1, signer)
+	return sigma, pf, nil
+}
+
+func (b *ABLS) verifyCombineBlind(blinded0 bls.G2Affine, rho fr.Element, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) (bls.G2Jac, error) {
+	blinded1, err := b.blindedPoint1(blinded0)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return unblind(b.verifyCombine(blinded0, blinded1, signers, sigmas, pfs), rho), nil
+}
@@ -0,0 +1,145 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlindBLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	blinded, rho := blindPoints(roMsg)
+	assert.False(t, blinded[0].Equal(&roMsg))
+
+	var signers []int
+	var sigmas, sigmasDleq []bls.G2Jac
+	var pfs []Pf
+	for i := 0; i <= ths; i++ {
+		sigma, err := m.psignBlind(blinded[0], m.pp.signers[i])
+		assert.NoError(t, err)
+		sigmaDleq, pf, err := m.pSignDleqBlind(blinded[0], m.pp.signers[i])
+		assert.NoError(t, err)
+
+		// Partials are checkable against the blinded input
+		assert.True(t, m.pverify(blinded[0], sigma, m.pp.pKeys[i]))
+		assert.True(t, m.pVerifyDleq(blinded[0], sigmaDleq, i, pf))
+
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		sigmasDleq = append(sigmasDleq, sigmaDleq)
+		pfs = append(pfs, pf)
+	}
+
+	msig := m.verifyCombineBlind(blinded[0], rho, signers, sigmas)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	msig = m.verifyCombineDleqBlind(blinded[0], rho, signers, sigmasDleq, pfs)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	// Unblinding with the wrong factor does not give a signature
+	_, otherRho := blindPoints(roMsg)
+	msig = m.verifyCombineBlind(blinded[0], otherRho, signers, sigmas)
+	assert.False(t, m.gverify(roMsg, msig))
+}
+
+func TestBlindABLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	blinded, rho := blindPoints(ro0Msg)
+	blinded1, err := m.blindedPoint1(blinded[0])
+	assert.NoError(t, err)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		sigma, pf, err := m.pSignBlind(blinded[0], m.pp.signers[i])
+		assert.NoError(t, err)
+		assert.True(t, m.pVerify(blinded[0], blinded1, sigma, i, pf))
+		assert.False(t, m.pVerify(ro0Msg, ro1Msg, sigma, i, pf))
+
+		// The second point is the signers' choice, not the requester's
+		var correlated bls.G2Affine
+		correlated.Double(&blinded[0])
+		assert.False(t, m.pVerify(blinded[0], correlated, sigma, i, pf))
+
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+
+	msig, err := m.verifyCombineBlind(blinded[0], rho, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, msig))
+}
+
+func TestBlindRejectsInvalidPoints(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+	a := NewABLS(n, n/2, GenABLSCRS(n))
+
+	var identity bls.G2Affine
+	_, err := m.psignBlind(identity, m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidPoint)
+
+	_, err = m.psignBlind(nonSubgroupG2(), m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidPoint)
+
+	_, _, err = a.pSignBlind(identity, a.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidPoint)
+}
+
+// nonSubgroupG2 returns a point on the G2 curve outside the prime-order subgroup
+func nonSubgroupG2() bls.G2Affine {
+	var p bls.G2Affine
+	var four fp.Element
+	four.SetInt64(4)
+	for x := int64(1); ; x++ {
+		// y^2 = x^3 + 4(1+u)
+		p.X.SetZero()
+		p.X.A0.SetInt64(x)
+		p.Y.Square(&p.X).Mul(&p.Y, &p.X)
+		p.Y.A0.Add(&p.Y.A0, &four)
+		p.Y.A1.Add(&p.Y.A1, &four)
+		if p.Y.Legendre() != 1 {
+			continue
+		}
+		p.Y.Sqrt(&p.Y)
+		if p.IsOnCurve() && !p.IsInSubGroup() {
+			return p
+		}
+	}
+}
+
+// nonSubgroupG1 returns a point on the G1 curve outside the prime-order subgroup
+func nonSubgroupG1() bls.G1Affine {
+	var p bls.G1Affine
+	var four fp.Element
+	four.SetInt64(4)
+	for x := int64(1); ; x++ {
+		// y^2 = x^3 + 4
+		p.X.SetInt64(x)
+		p.Y.Square(&p.X).Mul(&p.Y, &p.X)
+		p.Y.Add(&p.Y, &four)
+		if p.Y.Legendre() != 1 {
+			continue
+		}
+		p.Y.Sqrt(&p.Y)
+		if p.IsOnCurve() && !p.IsInSubGroup() {
+			return p
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package tss
 
 import (
-	"crypto/sha256"
-	"fmt"
 	"math/big"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -11,6 +9,9 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
 )
 
+// Hash-to-curve DST of the BLS12-381 signature ciphersuite with G2 signatures
+const blsSigDST = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"
+
 type BLSParty struct {
 	sKey  fr.Element
 	pKey  bls.G1Jac
@@ -26,6 +27,9 @@ type BLSCRS struct {
 	g2a     bls.G2Affine
 	domain  *fft.Domain
 	H       []fr.Element
+	dst     []byte
+	fp      [32]byte
+	fb      fixedBases
 }
 
 type BLSParams struct {
@@ -35,13 +39,37 @@ type BLSParams struct {
 }
 
 type BLS struct {
-	n   int
-	t   int
-	crs BLSCRS
-	pp  BLSParams
+	n         int
+	t         int
+	crs       BLSCRS
+	pp        BLSParams
+	policy    SignPolicy
+	batchDleq bool
 }
 
 func GenBLSCRS(n int) BLSCRS {
+	gen1, gen2, _, _ := bls.Generators()
+
+	var s1, s2 fr.Element
+	s1.SetRandom()
+	s2.SetRandom()
+
+	var g1 bls.G1Jac
+	var g2 bls.G2Jac
+	g1.ScalarMultiplication(&gen1, s1.BigInt(&big.Int{}))
+	g2.ScalarMultiplication(&gen2, s2.BigInt(&big.Int{}))
+
+	return genBLSCRS(n, g1, g2, []byte("DST"))
+}
+
+// GenBLSCRSStandard uses the standard generators and the hash-to-curve DST of
+// the BLS signature ciphersuite, as interoperable deployments (e.g. drand) do
+func GenBLSCRSStandard(n int) BLSCRS {
+	gen1, gen2, _, _ := bls.Generators()
+	return genBLSCRS(n, gen1, gen2, []byte(blsSigDST))
+}
+
+func genBLSCRS(n int, g1 bls.G1Jac, g2 bls.G2Jac, dst []byte) BLSCRS {
 	domain := fft.NewDomain(uint64(n))
 
 	H := make([]fr.Element, n)
@@ -52,23 +80,13 @@ func GenBLSCRS(n int) BLSCRS {
 		exp.Mul(&exp, &omH)
 	}
 
-	gen1, gen2, _, _ := bls.Generators()
-
-	var s1, s2 fr.Element
-	s1.SetRandom()
-	s2.SetRandom()
-
 	var (
-		g1      bls.G1Jac
 		g1a     bls.G1Affine
 		g1Inv   bls.G1Jac
 		g1InvAf bls.G1Affine
-		g2      bls.G2Jac
 		g2a     bls.G2Affine
 	)
 
-	g1.ScalarMultiplication(&gen1, s1.BigInt(&big.Int{}))
-	g2.ScalarMultiplication(&gen2, s2.BigInt(&big.Int{}))
 	g1a.FromJacobian(&g1)
 	g2a.FromJacobian(&g2)
 	g1Inv.Neg(&g1)
@@ -83,7 +101,25 @@ func GenBLSCRS(n int) BLSCRS {
 		g1InvAf: g1InvAf,
 		domain:  domain,
 		H:       H,
+		dst:     dst,
+		fp:      crsFingerprint("BLS-CRS", []bls.G1Affine{g1a}, []bls.G2Affine{g2a}, n, dst),
+		fb:      newFixedBases(DefaultFixedBaseWindow, g1a),
+	}
+}
+
+// SetFixedBaseWindow rebuilds the table of g1 with windows of w bits,
+// 1 <= w <= MaxFixedBaseWindow
+func (crs *BLSCRS) SetFixedBaseWindow(w int) error {
+	if err := validWindow(w); err != nil {
+		return err
 	}
+	crs.fb = newFixedBases(w, crs.g1a)
+	return nil
+}
+
+// g1^s
+func (b *BLS) mulG1(s fr.Element) bls.G1Jac {
+	return b.crs.fb.multiExp([]bls.G1Affine{b.crs.g1a}, s)
 }
 
 // Here t is the degree of the polynomial
@@ -99,6 +135,17 @@ func NewBLS(n, t int, crs BLSCRS) BLS {
 	return bls
 }
 
+// SetPolicy installs the policy every partial signing request must pass
+func (b *BLS) SetPolicy(p SignPolicy) {
+	b.policy = p
+}
+
+// SetBatchDleq makes DLEQ proofs carry their commitment form and
+// verifyCombineDleq check such proofs in batches
+func (b *BLS) SetBatchDleq(on bool) {
+	b.batchDleq = on
+}
+
 // (n,t) secret shared keys
 func (b *BLS) keyGen() {
 	sKeys := make([]fr.Element, b.n)
@@ -109,17 +156,15 @@ func (b *BLS) keyGen() {
 		sKeys[i].SetRandom()
 	}
 
-	var pk bls.G1Jac
-	var pkAf bls.G1Affine
-	pk.ScalarMultiplication(&b.crs.g1, sKeys[0].BigInt(&big.Int{}))
-	pkAf.FromJacobian(&pk)
+	pk := b.mulG1(sKeys[0])
+	pkAf := *new(bls.G1Affine).FromJacobian(&pk)
 
 	b.crs.domain.FFT(sKeys, fft.DIF)
 	fft.BitReverse(sKeys)
 
 	parties := make([]BLSParty, b.n)
 	for i := 0; i < b.n; i++ {
-		pKeys[i].ScalarMultiplication(&b.crs.g1, sKeys[i].BigInt(&big.Int{}))
+		pKeys[i] = b.mulG1(sKeys[i])
 		parties[i] = BLSParty{
 			sKey:  sKeys[i],
 			pKey:  pKeys[i],
@@ -135,48 +180,53 @@ func (b *BLS) keyGen() {
 	}
 }
 
+func (b *BLS) hashMsg(msg Message) (bls.G2Affine, error) {
+	return bls.HashToG2(msg, b.crs.dst)
+}
+
 // Takes the signing key and signs the message
-func (b *BLS) psign(msg Message, signer BLSParty) bls.G2Jac {
-	roMsgAf, err := bls.HashToG2(msg, []byte("DST"))
+func (b *BLS) psign(msg Message, signer BLSParty) (bls.G2Jac, error) {
+	if err := checkPolicy(b.policy, msg, signer.index); err != nil {
+		return bls.G2Jac{}, err
+	}
+
+	roMsgAf, err := b.hashMsg(msg)
 	if err != nil {
-		fmt.Printf("Signature error!")
+		return bls.G2Jac{}, err
 	}
+	return b.signPoint(roMsgAf, signer), nil
+}
 
+// Signs an already hashed message point; the caller is responsible for the policy check
+func (b *BLS) signPoint(roMsgAf bls.G2Affine, signer BLSParty) bls.G2Jac {
 	roMsg := *new(bls.G2Jac).FromAffine(&roMsgAf)
 	return *new(bls.G2Jac).ScalarMultiplication(&roMsg, signer.sKey.BigInt(&big.Int{}))
 }
 
 // Takes the msg, signature and signing key and verifies the signature
 func (b *BLS) pverify(roMsg bls.G2Affine, sigma bls.G2Jac, vk bls.G1Affine) bool {
-	sigmaAff := *new(bls.G2Affine).FromJacobian(&sigma)
+	sigmaAff, err := validG2Jac(&sigma)
+	if err != nil || vk.IsInfinity() {
+		return false
+	}
+	return b.pairingVerify(roMsg, sigmaAff, vk)
+}
+
+// The pairing equation alone, for signatures that were already validated
+func (b *BLS) pairingVerify(roMsg bls.G2Affine, sigmaAff bls.G2Affine, vk bls.G1Affine) bool {
 	res, _ := bls.PairingCheck([]bls.G1Affine{vk, b.crs.g1InvAf}, []bls.G2Affine{roMsg, sigmaAff})
 	return res
 }
 
+// Zero if fewer than t+1 partials verify, verifyCombineResult says why
 func (b *BLS) verifyCombine(msg bls.G2Affine, signers []int, sigmas []bls.G2Jac) bls.G2Jac {
-	var vfSigners []int
-	var lIdx []int
-	for i, idx := range signers {
-		if b.pverify(msg, sigmas[i], b.pp.pKeys[idx]) {
-			vfSigners = append(vfSigners, signers[i])
-			lIdx = append(lIdx, i)
-			if len(lIdx) == b.t+1 {
-				break
-			}
-		}
-	}
-
-	vfSigs := make([]bls.G2Affine, len(vfSigners))
-	for i, idx := range lIdx {
-		vfSigs[i].FromJacobian(&sigmas[idx])
-	}
-
-	return b.combine(vfSigners, vfSigs)
+	res, _ := b.verifyCombineResult(msg, signers, sigmas)
+	return res.Sigma
 }
 
 func (b *BLS) combine(signers []int, sigmas []bls.G2Affine) bls.G2Jac {
-	// If not enough signatures to combine return a empty value
-	if len(signers) <= b.t {
+	// If not enough valid signatures to combine return a empty value
+	if validQuorum(b.n, b.t, signers, sigmas) != nil {
 		return bls.G2Jac{}
 	}
 
@@ -188,7 +238,7 @@ func (b *BLS) combine(signers []int, sigmas []bls.G2Affine) bls.G2Jac {
 	lagH := GetLagAt0(uint64(b.n), indices)
 
 	var thSig bls.G2Jac
-	thSig.MultiExp(sigmas, lagH, ecc.MultiExpConfig{})
+	thSig.MultiExp(sigmas[:b.t+1], lagH, ecc.MultiExpConfig{})
 
 	return thSig
 }
@@ -200,102 +250,144 @@ func (b *BLS) combine(signers []int, sigmas []bls.G2Affine) bls.G2Jac {
 type Pf struct {
 	c fr.Element
 	z fr.Element
+	// The same proof in commitment form, only attached with SetBatchDleq
+	cmt *DleqCmtPf
 }
 
-func getFSChal(val1 []bls.G1Jac, val2 []bls.G2Jac) fr.Element {
-	n1 := len(val1)
-	n2 := len(val2)
-	hMsg := make([]byte, n1*48+n2*96)
-
-	for i, v := range val1 {
-		valAff := *new(bls.G1Affine).FromJacobian(&v)
-		mBytes := valAff.Bytes()
-		copy(hMsg[i*48:(i+1)*48], mBytes[:])
-	}
+// Binds the proof to the CRS, the group key, the signer and the message
+func (b *BLS) dleqChallenge(ctx []byte, index int, roMsg bls.G2Affine, pKey bls.G1Affine, sigma bls.G2Affine, a bls.G1Affine, hm bls.G2Affine) fr.Element {
+	t := newTranscript("BLS-PARTIAL-DLEQ")
+	t.appendBytes("crs", b.crs.fp[:])
+	t.appendG1("pk", b.pp.pk)
+	t.appendUint64("index", uint64(index))
+	t.appendBytes("context", ctx)
+	t.appendG2("msg", roMsg)
+	t.appendG1("pkey", pKey)
+	t.appendG2("sigma", sigma)
+	t.appendG1("a", a)
+	t.appendG2("b", hm)
+	return t.challenge()
+}
 
-	shift := n1 * 48
-	for i, v := range val2 {
-		valAff := *new(bls.G2Affine).FromJacobian(&v)
-		mBytes := valAff.Bytes()
-		copy(hMsg[shift+i*48:shift+(i+1)*48], mBytes[:])
+// Computing the Chaum-Pedersen Sigma protocol
+func (b *BLS) cpProve(ctx []byte, roMsg bls.G2Affine, sigma bls.G2Jac, signer BLSParty) Pf {
+	pf, c := b.cpProveCmt(ctx, roMsg, sigma, signer)
+	if b.batchDleq {
+		return Pf{c: c, z: pf.z, cmt: &pf}
 	}
-
-	hFunc := sha256.New()
-	hFunc.Reset()
-	return *new(fr.Element).SetBytes(hFunc.Sum(hMsg))
+	return Pf{c: c, z: pf.z}
 }
 
-// Computing the Chaum-Pedersen Sigma protocol
-func (b *BLS) cpProve(pk bls.G1Jac, roMsg bls.G2Jac, sigma bls.G2Jac, sec fr.Element) Pf {
+// Same proof, keeping the commitments instead of the challenge
+func (b *BLS) cpProveCmt(ctx []byte, roMsg bls.G2Affine, sigma bls.G2Jac, signer BLSParty) (DleqCmtPf, fr.Element) {
 	var r fr.Element
 	r.SetRandom()
 	rInt := r.BigInt(&big.Int{})
-	gr := *new(bls.G1Jac).ScalarMultiplication(&b.crs.g1, rInt)
-	hmr := *new(bls.G2Jac).ScalarMultiplication(&roMsg, rInt)
 
-	c := getFSChal([]bls.G1Jac{pk, gr}, []bls.G2Jac{roMsg, hmr})
+	var pf DleqCmtPf
+	a := b.mulG1(r)
+	pf.a.FromJacobian(&a)
+	pf.b.ScalarMultiplication(&roMsg, rInt)
 
-	var z fr.Element
-	z.Mul(&c, &sec)
-	z.Add(&z, &r)
+	pKey := *new(bls.G1Affine).FromJacobian(&signer.pKey)
+	sigmaAf := *new(bls.G2Affine).FromJacobian(&sigma)
+	c := b.dleqChallenge(ctx, signer.index, roMsg, pKey, sigmaAf, pf.a, pf.b)
 
-	return Pf{c, z}
+	pf.z.Mul(&c, &signer.sKey)
+	pf.z.Add(&pf.z, &r)
+	return pf, c
 }
 
 // Checks the correctness of the Chaum-Pedersen Proof
-func (b *BLS) cpVerify(pk bls.G1Jac, roMsg bls.G2Jac, sigma bls.G2Jac, pf Pf) bool {
+func (b *BLS) cpVerify(ctx []byte, roMsgAf bls.G2Affine, index int, sigma bls.G2Jac, pf Pf) bool {
+	if index < 0 || index >= b.n {
+		return false
+	}
+	pKey := b.pp.pKeys[index]
 	zInt := pf.z.BigInt(&big.Int{})
 	cInt := pf.c.BigInt(&big.Int{})
 
-	pkC := *new(bls.G1Jac).ScalarMultiplication(&pk, cInt)
+	pkC := *new(bls.G1Jac).FromAffine(&pKey)
+	pkC.ScalarMultiplication(&pkC, cInt)
 	sigmaC := *new(bls.G2Jac).ScalarMultiplication(&sigma, cInt)
 
-	gZ := *new(bls.G1Jac).ScalarMultiplication(&b.crs.g1, zInt)
+	roMsg := *new(bls.G2Jac).FromAffine(&roMsgAf)
+	gZ := b.mulG1(pf.z)
 	hZ := *new(bls.G2Jac).ScalarMultiplication(&roMsg, zInt)
 
-	gZ = *gZ.SubAssign(&pkC)
-	hZ = *hZ.SubAssign(&sigmaC)
+	gZ.SubAssign(&pkC)
+	hZ.SubAssign(&sigmaC)
 
-	cLocal := getFSChal([]bls.G1Jac{pk, gZ}, []bls.G2Jac{roMsg, hZ})
+	a := *new(bls.G1Affine).FromJacobian(&gZ)
+	hm := *new(bls.G2Affine).FromJacobian(&hZ)
+	sigmaAf := *new(bls.G2Affine).FromJacobian(&sigma)
+	cLocal := b.dleqChallenge(ctx, index, roMsgAf, pKey, sigmaAf, a, hm)
 
 	return pf.c.Equal(&cLocal)
 }
 
 // Partial signature along
-func (b *BLS) pSignDleq(msg Message, signer BLSParty) (bls.G2Jac, Pf) {
-	roMsgAf, _ := bls.HashToG2(msg, []byte("DST"))
+func (b *BLS) pSignDleq(msg Message, signer BLSParty) (bls.G2Jac, Pf, error) {
+	return b.pSignDleqCtx(nil, msg, signer)
+}
+
+// Partial signature whose proof is additionally bound to ctx
+func (b *BLS) pSignDleqCtx(ctx []byte, msg Message, signer BLSParty) (bls.G2Jac, Pf, error) {
+	if err := checkPolicy(b.policy, msg, signer.index); err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+
+	roMsgAf, err := b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	sigma, pf := b.signPointDleq(ctx, roMsgAf, signer)
+	return sigma, pf, nil
+}
+
+// Signs an already hashed message point and proves it; the caller is responsible for the policy check
+func (b *BLS) signPointDleq(ctx []byte, roMsgAf bls.G2Affine, signer BLSParty) (bls.G2Jac, Pf) {
 	roMsg := *new(bls.G2Jac).FromAffine(&roMsgAf)
 	sigma := *new(bls.G2Jac).ScalarMultiplication(&roMsg, signer.sKey.BigInt(&big.Int{}))
 
-	pf := b.cpProve(signer.pKey, roMsg, sigma, signer.sKey)
+	pf := b.cpProve(ctx, roMsgAf, sigma, signer)
 	return sigma, pf
 }
 
-func (b *BLS) pVerifyDleq(roMsgAf bls.G2Affine, sigma bls.G2Jac, vkAf bls.G1Affine, pf Pf) bool {
-	vk := *new(bls.G1Jac).FromAffine(&vkAf)
-	roMsg := *new(bls.G2Jac).FromAffine(&roMsgAf)
-	return b.cpVerify(vk, roMsg, sigma, pf)
+// Verifies the partial signature of the party at index
+func (b *BLS) pVerifyDleq(roMsgAf bls.G2Affine, sigma bls.G2Jac, index int, pf Pf) bool {
+	if _, err := validG2Jac(&sigma); err != nil {
+		return false
+	}
+	return b.dleqVerify(roMsgAf, sigma, index, pf)
+}
+
+// The proof check alone, for signatures that were already validated
+func (b *BLS) dleqVerify(roMsgAf bls.G2Affine, sigma bls.G2Jac, index int, pf Pf) bool {
+	return b.cpVerify(nil, roMsgAf, index, sigma, pf)
 }
 
+// With SetBatchDleq, proofs that all carry their commitment form are
+// verified in batches; otherwise every proof is checked on its own
 func (b *BLS) verifyCombineDleq(msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []Pf) bls.G2Jac {
-	var vfSigners []int
-	var lIdx []int
-	for i, idx := range signers {
-		if b.pVerifyDleq(msg, sigmas[i], b.pp.pKeys[idx], pfs[i]) {
-			vfSigners = append(vfSigners, signers[i])
-			lIdx = append(lIdx, i)
-			if len(lIdx) == b.t+1 {
-				break
-			}
+	if b.batchDleq {
+		if cmtPfs, ok := dleqCommitments(pfs); ok {
+			return b.verifyCombineDleqBatch(msg, nil, signers, sigmas, cmtPfs)
 		}
 	}
+	res, _ := b.verifyCombineDleqResult(msg, signers, sigmas, pfs)
+	return res.Sigma
+}
 
-	vfSigs := make([]bls.G2Affine, len(vfSigners))
-	for i, idx := range lIdx {
-		vfSigs[i].FromJacobian(&sigmas[idx])
+func dleqCommitments(pfs []Pf) ([]DleqCmtPf, bool) {
+	cmtPfs := make([]DleqCmtPf, len(pfs))
+	for i := range pfs {
+		if pfs[i].cmt == nil {
+			return nil, false
+		}
+		cmtPfs[i] = *pfs[i].cmt
 	}
-
-	return b.combine(vfSigners, vfSigs)
+	return cmtPfs, true
 }
 
 func (b *BLS) gverify(roMsg bls.G2Affine, sigma bls.G2Jac) bool {
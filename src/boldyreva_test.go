@@ -22,7 +22,8 @@ func TestBLS(t *testing.T) {
 	var sigmas []bls.G2Jac
 	for i := 0; i < ths+1; i++ {
 		signers = append(signers, i)
-		sigmas = append(sigmas, m.psign(msg, m.pp.signers[i]))
+		sigma, _ := m.psign(msg, m.pp.signers[i])
+		sigmas = append(sigmas, sigma)
 	}
 
 	msig := m.verifyCombine(roMsg, signers, sigmas)
@@ -45,7 +46,7 @@ func TestBLSDleq(t *testing.T) {
 	var pfs []Pf
 	for i := 0; i < ths+1; i++ {
 		signers = append(signers, i)
-		sigma, pf := m.pSignDleq(msg, m.pp.signers[i])
+		sigma, pf, _ := m.pSignDleq(msg, m.pp.signers[i])
 		sigmas = append(sigmas, sigma)
 		pfs = append(pfs, pf)
 	}
@@ -66,35 +67,40 @@ func BenchmarkBLS(b *testing.B) {
 	m := NewBLS(n, ths, crs)
 
 	var sigma bls.G2Jac
-	b.Run("B1-pSign", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			sigma = m.psign(msg, m.pp.signers[0])
-		}
-	})
-
+	var pf Pf
 	pk0Aff := *new(bls.G1Affine).FromJacobian(&m.pp.signers[0].pKey)
-	b.Run("B1-pVerify", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			m.pverify(roMsg, sigma, pk0Aff)
-		}
-	})
+	for _, w := range benchWindows {
+		setWindowBLS(&m.crs, w)
+		b.Run(fmt.Sprintf("w=%d", w), func(b *testing.B) {
+			b.Run("B1-pSign", func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					sigma, _ = m.psign(msg, m.pp.signers[0])
+				}
+			})
 
-	var pf Pf
-	b.Run("B2-pSign", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			sigma, pf = m.pSignDleq(msg, m.pp.signers[0])
-		}
-	})
+			b.Run("B1-pVerify", func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.pverify(roMsg, sigma, pk0Aff)
+				}
+			})
 
-	b.Run("B2-pVerify", func(b *testing.B) {
-		b.ResetTimer()
-		for i := 0; i < b.N; i++ {
-			m.pVerifyDleq(roMsg, sigma, pk0Aff, pf)
-		}
-	})
+			b.Run("B2-pSign", func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					sigma, pf, _ = m.pSignDleq(msg, m.pp.signers[0])
+				}
+			})
+
+			b.Run("B2-pVerify", func(b *testing.B) {
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.pVerifyDleq(roMsg, sigma, 0, pf)
+				}
+			})
+		})
+	}
 }
 
 func BenchmarkBLSAgg(b *testing.B) {
@@ -108,18 +114,18 @@ func BenchmarkBLSAgg(b *testing.B) {
 	}
 
 	msg := []byte("hello world")
-	roMsg, _ := bls.HashToG2(msg, []byte{})
 
 	for _, tc := range testCases {
 		crs := GenBLSCRS(tc.n)
 		m := NewBLS(tc.n, tc.t-1, crs)
+		roMsg, _ := m.hashMsg(msg)
 
 		// Picking the first t nodes
 		signers := make([]int, tc.t)
 		sigmas := make([]bls.G2Jac, tc.t)
 		for i := 0; i < tc.t; i++ {
 			signers[i] = i
-			sigmas[i] = m.psign(msg, m.pp.signers[i])
+			sigmas[i], _ = m.psign(msg, m.pp.signers[i])
 		}
 
 		var sigma bls.G2Jac
@@ -130,6 +136,21 @@ func BenchmarkBLSAgg(b *testing.B) {
 			}
 		})
 
+		b.Run(tc.name+"-B1-agg-batch", func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sigma = m.verifyCombineBatch(roMsg, signers, sigmas)
+			}
+		})
+
+		b.Run(tc.name+"-B1-agg-optimistic", func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				res, _ := m.optimisticCombine(roMsg, signers, sigmas)
+				sigma = res.Sigma
+			}
+		})
+
 		b.Run(tc.name+"-B1-agg-no-verify", func(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
@@ -144,7 +165,7 @@ func BenchmarkBLSAgg(b *testing.B) {
 
 		pfs := make([]Pf, tc.t)
 		for i := 0; i < tc.t; i++ {
-			_, pfs[i] = m.pSignDleq(msg, m.pp.signers[i])
+			_, pfs[i], _ = m.pSignDleq(msg, m.pp.signers[i])
 		}
 		b.Run(tc.name+"-B2-agg", func(b *testing.B) {
 			b.ResetTimer()
@@ -153,6 +174,19 @@ func BenchmarkBLSAgg(b *testing.B) {
 			}
 		})
 
+		m.SetBatchDleq(true)
+		batchPfs := make([]Pf, tc.t)
+		for i := 0; i < tc.t; i++ {
+			_, batchPfs[i], _ = m.pSignDleq(msg, m.pp.signers[i])
+		}
+		b.Run(tc.name+"-B2-agg-batch", func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sigma = m.verifyCombineDleq(roMsg, signers, sigmas, batchPfs)
+			}
+		})
+		m.SetBatchDleq(false)
+
 		b.Run(tc.name+"-ver", func(b *testing.B) {
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
@@ -0,0 +1,475 @@
+package tss
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+/*
+* Threshold hashed ElGamal under the group key pk = g1^s.
+*
+* Boldyreva keys: c0 = g1^k and the shared secret is pk^k = c0^s. Party i
+* publishes c0^{s_i} with a Chaum-Pedersen proof that it has the same discrete
+* log as pKey_i = g1^{s_i}.
+*
+* Adaptive keys: c0 = g1^k, c1 = h1^k. Party i publishes c0^{s_i} c1^{r_i} and
+* proves it uses the opening (s_i, r_i, u_i) of pKey_i; since r(0) = 0 the
+* interpolation gives g1^{ks} = pk^k.
+*
+* The shared secret keys AES-GCM over the message. As in TDH2, the encryptor
+* proves that c0 and c1 share the exponent k, for Boldyreva keys c1 = gBar^k
+* under a generator nobody knows the log of. The proof hashes the label and the
+* body, so a share is only issued for the ciphertext it was made for and c0 can
+* not be lifted into a ciphertext of the attacker's choice.
+ */
+
+const (
+	ctElGamal         byte = 1
+	ctElGamalAdaptive byte = 2
+)
+
+const elgamalGenDST = "TSS-ELGAMAL-GBAR-V1"
+
+type elgamalCiphertext struct {
+	kind  byte
+	c0    bls.G1Affine
+	c1    bls.G1Affine
+	label []byte
+	e     fr.Element
+	f     fr.Element
+	body  []byte
+}
+
+// Second generator of the Boldyreva ciphertexts
+func elgamalGen() bls.G1Affine {
+	gBar, err := bls.HashToG1([]byte("generator"), []byte(elgamalGenDST))
+	if err != nil {
+		panic(err)
+	}
+	return gBar
+}
+
+func newElGamal(kind byte, label []byte) (elgamalCiphertext, error) {
+	if uint64(len(label)) > math.MaxUint32 {
+		return elgamalCiphertext{}, fmt.Errorf("%w: label longer than 2^32-1 bytes", ErrInvalidCiphertext)
+	}
+	return elgamalCiphertext{kind: kind, label: append([]byte{}, label...)}, nil
+}
+
+// Encoded as kind || c0 || c1 || len(label) || label, authenticated by AES-GCM
+func (ct *elgamalCiphertext) header() []byte {
+	c0Bytes, c1Bytes := ct.c0.Bytes(), ct.c1.Bytes()
+	buf := append([]byte{ct.kind}, c0Bytes[:]...)
+	buf = append(buf, c1Bytes[:]...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(ct.label)))
+	return append(buf, ct.label...)
+}
+
+// Encoded as header || e || f || body
+func (ct *elgamalCiphertext) bytes() []byte {
+	return append(appendScalars(ct.header(), &ct.e, &ct.f), ct.body...)
+}
+
+func decodeElGamal(data []byte, kind byte) (elgamalCiphertext, error) {
+	ct := elgamalCiphertext{kind: kind}
+	if len(data) < 1+2*bls.SizeOfG1AffineCompressed+4 || data[0] != kind {
+		return ct, ErrInvalidCiphertext
+	}
+	rest := data[1:]
+	for _, p := range []*bls.G1Affine{&ct.c0, &ct.c1} {
+		if _, err := p.SetBytes(rest[:bls.SizeOfG1AffineCompressed]); err != nil {
+			return ct, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+		}
+		if p.IsInfinity() {
+			return ct, ErrInvalidCiphertext
+		}
+		rest = rest[bls.SizeOfG1AffineCompressed:]
+	}
+	labelLen := uint64(binary.BigEndian.Uint32(rest))
+	rest = rest[4:]
+	if uint64(len(rest)) < labelLen+2*fr.Bytes {
+		return ct, ErrInvalidCiphertext
+	}
+	ct.label = append([]byte{}, rest[:labelLen]...)
+	rest = rest[labelLen:]
+	if err := setScalars(rest, &ct.e, &ct.f); err != nil {
+		return ct, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	ct.body = append([]byte{}, rest[2*fr.Bytes:]...)
+	return ct, nil
+}
+
+func elgamalChallenge(fp [32]byte, pk bls.G1Affine, ct *elgamalCiphertext, u bls.G1Jac, uBar bls.G1Jac) fr.Element {
+	pts := bls.BatchJacobianToAffineG1([]bls.G1Jac{u, uBar})
+	t := newTranscript("ELGAMAL-CT")
+	t.appendBytes("crs", fp[:])
+	t.appendG1("pk", pk)
+	t.appendBytes("header", ct.header())
+	t.appendBytes("body", ct.body)
+	t.appendG1("u", pts[0], pts[1])
+	return t.challenge()
+}
+
+// Proves log_g(c0) = log_gBar(c1) = k, after the body is sealed
+func (ct *elgamalCiphertext) prove(fp [32]byte, pk bls.G1Affine, g bls.G1Affine, gBar bls.G1Affine, k fr.Element) {
+	var w fr.Element
+	w.SetRandom()
+	wInt := w.BigInt(&big.Int{})
+	var u, uBar bls.G1Jac
+	u.FromAffine(&g).ScalarMultiplication(&u, wInt)
+	uBar.FromAffine(&gBar).ScalarMultiplication(&uBar, wInt)
+
+	ct.e = elgamalChallenge(fp, pk, ct, u, uBar)
+	ct.f.Mul(&k, &ct.e)
+	ct.f.Add(&ct.f, &w)
+}
+
+func (ct *elgamalCiphertext) verify(fp [32]byte, pk bls.G1Affine, g bls.G1Affine, gBar bls.G1Affine) bool {
+	var u, uBar bls.G1Jac
+	u.MultiExp([]bls.G1Affine{g, ct.c0}, []fr.Element{ct.f, *new(fr.Element).Neg(&ct.e)}, ecc.MultiExpConfig{})
+	uBar.MultiExp([]bls.G1Affine{gBar, ct.c1}, []fr.Element{ct.f, *new(fr.Element).Neg(&ct.e)}, ecc.MultiExpConfig{})
+	e := elgamalChallenge(fp, pk, ct, u, uBar)
+	return ct.e.Equal(&e)
+}
+
+// Every ciphertext has a fresh shared secret, so a fixed nonce is safe
+func elgamalAEAD(secret bls.G1Affine, header []byte) (cipher.AEAD, error) {
+	secretBytes := secret.Bytes()
+	h := sha256.New()
+	h.Write([]byte("TSS-ELGAMAL-KDF"))
+	h.Write(secretBytes[:])
+	h.Write(header)
+
+	block, err := aes.NewCipher(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func elgamalSeal(ct *elgamalCiphertext, secret bls.G1Affine, msg []byte) error {
+	aead, err := elgamalAEAD(secret, ct.header())
+	if err != nil {
+		return err
+	}
+	ct.body = aead.Seal(nil, make([]byte, aead.NonceSize()), msg, ct.header())
+	return nil
+}
+
+func elgamalOpen(ct *elgamalCiphertext, secret bls.G1Affine) ([]byte, error) {
+	aead, err := elgamalAEAD(secret, ct.header())
+	if err != nil {
+		return nil, err
+	}
+	msg, err := aead.Open(nil, make([]byte, aead.NonceSize()), ct.body, ct.header())
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return msg, nil
+}
+
+// Interpolates the shared secret from t+1 decryption shares
+func combineShares(n int, signers []int, shares []bls.G1Affine) bls.G1Affine {
+	lagH := GetLagAt0(uint64(n), signers)
+	var secret bls.G1Affine
+	secret.MultiExp(shares, lagH, ecc.MultiExpConfig{})
+	return secret
+}
+
+// Keeps the first t+1 shares with a fresh, in-range index that verify
+func selectShares(n, t int, signers []int, shares []bls.G1Jac, verify func(pos int) bool) ([]int, []bls.G1Jac) {
+	var vfSigners []int
+	var vfShares []bls.G1Jac
+	seen := make(map[int]bool, t+1)
+	for pos, idx := range signers {
+		if len(vfSigners) == t+1 {
+			break
+		}
+		if idx < 0 || idx >= n || seen[idx] || !verify(pos) {
+			continue
+		}
+		seen[idx] = true
+		vfSigners = append(vfSigners, idx)
+		vfShares = append(vfShares, shares[pos])
+	}
+	return vfSigners, vfShares
+}
+
+/**************************
+	BOLDYREVA
+***************************/
+
+// The label is public and bound to the ciphertext, policies see it in data
+func (b *BLS) encrypt(msg []byte, label []byte) ([]byte, error) {
+	var k fr.Element
+	k.SetRandom()
+	kInt := k.BigInt(&big.Int{})
+
+	ct, err := newElGamal(ctElGamal, label)
+	if err != nil {
+		return nil, err
+	}
+	gBar := elgamalGen()
+	ct.c0.ScalarMultiplication(&b.crs.g1a, kInt)
+	ct.c1.ScalarMultiplication(&gBar, kInt)
+	var secret bls.G1Affine
+	secret.ScalarMultiplication(&b.pp.pk, kInt)
+
+	if err := elgamalSeal(&ct, secret, msg); err != nil {
+		return nil, err
+	}
+	ct.prove(b.crs.fp, b.pp.pk, b.crs.g1a, gBar, k)
+	return ct.bytes(), nil
+}
+
+// Decodes a ciphertext and checks that it is well formed
+func (b *BLS) decodeCiphertext(data []byte) (elgamalCiphertext, error) {
+	ct, err := decodeElGamal(data, ctElGamal)
+	if err != nil {
+		return ct, err
+	}
+	if !ct.verify(b.crs.fp, b.pp.pk, b.crs.g1a, elgamalGen()) {
+		return ct, fmt.Errorf("%w: malformed", ErrInvalidCiphertext)
+	}
+	return ct, nil
+}
+
+func (b *BLS) decChallenge(index int, c0 bls.G1Jac, pKey bls.G1Jac, share bls.G1Jac, gr bls.G1Jac, c0r bls.G1Jac) fr.Element {
+	pts := bls.BatchJacobianToAffineG1([]bls.G1Jac{c0, pKey, share, gr, c0r})
+	t := newTranscript("BLS-DEC-SHARE")
+	t.appendBytes("crs", b.crs.fp[:])
+	t.appendG1("pk", b.pp.pk)
+	t.appendUint64("index", uint64(index))
+	t.appendG1("c0", pts[0])
+	t.appendG1("pkey", pts[1])
+	t.appendG1("share", pts[2])
+	t.appendG1("a", pts[3], pts[4])
+	return t.challenge()
+}
+
+// Chaum-Pedersen proof that log_g1(pKey) = log_c0(share)
+func (b *BLS) decProve(index int, c0 bls.G1Jac, pKey bls.G1Jac, share bls.G1Jac, sec fr.Element) Pf {
+	var r fr.Element
+	r.SetRandom()
+	rInt := r.BigInt(&big.Int{})
+	gr := b.mulG1(r)
+	c0r := *new(bls.G1Jac).ScalarMultiplication(&c0, rInt)
+
+	c := b.decChallenge(index, c0, pKey, share, gr, c0r)
+
+	var z fr.Element
+	z.Mul(&c, &sec)
+	z.Add(&z, &r)
+
+	return Pf{c: c, z: z}
+}
+
+func (b *BLS) decVerify(index int, c0 bls.G1Jac, pKey bls.G1Jac, share bls.G1Jac, pf Pf) bool {
+	zInt := pf.z.BigInt(&big.Int{})
+	cInt := pf.c.BigInt(&big.Int{})
+
+	pkC := *new(bls.G1Jac).ScalarMultiplication(&pKey, cInt)
+	shareC := *new(bls.G1Jac).ScalarMultiplication(&share, cInt)
+
+	gZ := b.mulG1(pf.z)
+	c0Z := *new(bls.G1Jac).ScalarMultiplication(&c0, zInt)
+
+	gZ.SubAssign(&pkC)
+	c0Z.SubAssign(&shareC)
+
+	cLocal := b.decChallenge(index, c0, pKey, share, gZ, c0Z)
+	return pf.c.Equal(&cLocal)
+}
+
+// Decryption share of a party; the policy sees the ciphertext
+func (b *BLS) decryptShare(data []byte, signer BLSParty) (bls.G1Jac, Pf, error) {
+	ct, err := b.decodeCiphertext(data)
+	if err != nil {
+		return bls.G1Jac{}, Pf{}, err
+	}
+	if err := checkPolicy(b.policy, data, signer.index); err != nil {
+		return bls.G1Jac{}, Pf{}, err
+	}
+
+	c0 := *new(bls.G1Jac).FromAffine(&ct.c0)
+	share := *new(bls.G1Jac).ScalarMultiplication(&c0, signer.sKey.BigInt(&big.Int{}))
+	pf := b.decProve(signer.index, c0, signer.pKey, share, signer.sKey)
+	return share, pf, nil
+}
+
+func (b *BLS) verifyDecryptShare(data []byte, index int, share bls.G1Jac, pf Pf) bool {
+	ct, err := b.decodeCiphertext(data)
+	if err != nil || index < 0 || index >= b.n {
+		return false
+	}
+	return b.verifyShare(&ct, index, share, pf)
+}
+
+func (b *BLS) verifyShare(ct *elgamalCiphertext, index int, share bls.G1Jac, pf Pf) bool {
+	if validG1(new(bls.G1Affine).FromJacobian(&share)) != nil {
+		return false
+	}
+	c0 := *new(bls.G1Jac).FromAffine(&ct.c0)
+	pKey := *new(bls.G1Jac).FromAffine(&b.pp.pKeys[index])
+	return b.decVerify(index, c0, pKey, share, pf)
+}
+
+// Verifies the shares and decrypts with the first t+1 valid ones
+func (b *BLS) combineDecrypt(data []byte, signers []int, shares []bls.G1Jac, pfs []Pf) ([]byte, error) {
+	ct, err := b.decodeCiphertext(data)
+	if err != nil {
+		return nil, err
+	}
+
+	vfSigners, vfShares := selectShares(b.n, b.t, signers, shares, func(pos int) bool {
+		return b.verifyShare(&ct, signers[pos], shares[pos], pfs[pos])
+	})
+	if len(vfSigners) <= b.t {
+		return nil, ErrCombineFailed
+	}
+
+	secret := combineShares(b.n, vfSigners, bls.BatchJacobianToAffineG1(vfShares))
+	return elgamalOpen(&ct, secret)
+}
+
+/**************************
+	ADAPTIVE BLS
+***************************/
+
+func (b *ABLS) encrypt(msg []byte, label []byte) ([]byte, error) {
+	var k fr.Element
+	k.SetRandom()
+	kInt := k.BigInt(&big.Int{})
+
+	ct, err := newElGamal(ctElGamalAdaptive, label)
+	if err != nil {
+		return nil, err
+	}
+	ct.c0.ScalarMultiplication(&b.crs.g1a, kInt)
+	ct.c1.ScalarMultiplication(&b.crs.h1a, kInt)
+	var secret bls.G1Affine
+	secret.ScalarMultiplication(&b.pp.pk, kInt)
+
+	if err := elgamalSeal(&ct, secret, msg); err != nil {
+		return nil, err
+	}
+	ct.prove(b.crs.fp, b.pp.pk, b.crs.g1a, b.crs.h1a, k)
+	return ct.bytes(), nil
+}
+
+func (b *ABLS) decodeCiphertext(data []byte) (elgamalCiphertext, error) {
+	ct, err := decodeElGamal(data, ctElGamalAdaptive)
+	if err != nil {
+		return ct, err
+	}
+	if !ct.verify(b.crs.fp, b.pp.pk, b.crs.g1a, b.crs.h1a) {
+		return ct, fmt.Errorf("%w: malformed", ErrInvalidCiphertext)
+	}
+	return ct, nil
+}
+
+func (b *ABLS) decChallenge(index int, c0 bls.G1Affine, c1 bls.G1Affine, pKey bls.G1Jac, share bls.G1Jac, x bls.G1Jac, y bls.G1Jac) fr.Element {
+	pts := bls.BatchJacobianToAffineG1([]bls.G1Jac{pKey, share, x, y})
+	t := newTranscript("ABLS-DEC-SHARE")
+	t.appendBytes("crs", b.crs.fp[:])
+	t.appendG1("pk", b.pp.pk)
+	t.appendUint64("index", uint64(index))
+	t.appendG1("c", c0, c1)
+	t.appendG1("pkey", pts[0])
+	t.appendG1("share", pts[1])
+	t.appendG1("xy", pts[2], pts[3])
+	return t.challenge()
+}
+
+// Proves knowledge of (s, r, u) with pKey = g1^s h1^r v1^u and share = c0^s c1^r
+func (b *ABLS) decProve(c0 bls.G1Affine, c1 bls.G1Affine, share bls.G1Jac, signer ABLSParty) SigmaPf {
+	var hs, hr, hu fr.Element
+	hs.SetRandom()
+	hr.SetRandom()
+	hu.SetRandom()
+
+	var y bls.G1Jac
+	x := b.commitG1(hs, hr, hu)
+	y.MultiExp([]bls.G1Affine{c0, c1}, []fr.Element{hs, hr}, ecc.MultiExpConfig{})
+
+	c := b.decChallenge(signer.index, c0, c1, signer.pKey, share, x, y)
+
+	var zs, zr, zu fr.Element
+	zs.Add(zs.Mul(&c, &signer.sKey), &hs)
+	zr.Add(zr.Mul(&c, &signer.rKey), &hr)
+	zu.Add(zu.Mul(&c, &signer.uKey), &hu)
+
+	return SigmaPf{c, zs, zr, zu}
+}
+
+func (b *ABLS) decVerify(index int, c0 bls.G1Affine, c1 bls.G1Affine, pKey bls.G1Jac, share bls.G1Jac, pf SigmaPf) bool {
+	cInt := pf.c.BigInt(&big.Int{})
+	pkC := *new(bls.G1Jac).ScalarMultiplication(&pKey, cInt)
+	shareC := *new(bls.G1Jac).ScalarMultiplication(&share, cInt)
+
+	var y bls.G1Jac
+	x := b.commitG1(pf.zs, pf.zr, pf.zu)
+	y.MultiExp([]bls.G1Affine{c0, c1}, []fr.Element{pf.zs, pf.zr}, ecc.MultiExpConfig{})
+	x.SubAssign(&pkC)
+	y.SubAssign(&shareC)
+
+	cLocal := b.decChallenge(index, c0, c1, pKey, share, x, y)
+	return pf.c.Equal(&cLocal)
+}
+
+func (b *ABLS) decryptShare(data []byte, signer ABLSParty) (bls.G1Jac, SigmaPf, error) {
+	ct, err := b.decodeCiphertext(data)
+	if err != nil {
+		return bls.G1Jac{}, SigmaPf{}, err
+	}
+	if err := checkPolicy(b.policy, data, signer.index); err != nil {
+		return bls.G1Jac{}, SigmaPf{}, err
+	}
+
+	var share bls.G1Jac
+	share.MultiExp([]bls.G1Affine{ct.c0, ct.c1}, []fr.Element{signer.sKey, signer.rKey}, ecc.MultiExpConfig{})
+	pf := b.decProve(ct.c0, ct.c1, share, signer)
+	return share, pf, nil
+}
+
+func (b *ABLS) verifyDecryptShare(data []byte, index int, share bls.G1Jac, pf SigmaPf) bool {
+	ct, err := b.decodeCiphertext(data)
+	if err != nil || index < 0 || index >= b.n {
+		return false
+	}
+	return b.verifyShare(&ct, index, share, pf)
+}
+
+func (b *ABLS) verifyShare(ct *elgamalCiphertext, index int, share bls.G1Jac, pf SigmaPf) bool {
+	if validG1(new(bls.G1Affine).FromJacobian(&share)) != nil {
+		return false
+	}
+	pKey := *new(bls.G1Jac).FromAffine(&b.pp.pKeys[index])
+	return b.decVerify(index, ct.c0, ct.c1, pKey, share, pf)
+}
+
+func (b *ABLS) combineDecrypt(data []byte, signers []int, shares []bls.G1Jac, pfs []SigmaPf) ([]byte, error) {
+	ct, err := b.decodeCiphertext(data)
+	if err != nil {
+		return nil, err
+	}
+
+	vfSigners, vfShares := selectShares(b.n, b.t, signers, shares, func(pos int) bool {
+		return b.verifyShare(&ct, signers[pos], shares[pos], pfs[pos])
+	})
+	if len(vfSigners) <= b.t {
+		return nil, ErrCombineFailed
+	}
+
+	secret := combineShares(b.n, vfSigners, bls.BatchJacobianToAffineG1(vfShares))
+	return elgamalOpen(&ct, secret)
+}
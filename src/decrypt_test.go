@@ -0,0 +1,146 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptBLS(t *testing.T) {
+	secret := []byte("vault entry")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+
+	ct, err := m.encrypt(secret, []byte("vault/42"))
+	assert.NoError(t, err)
+
+	var signers []int
+	var shares []bls.G1Jac
+	var pfs []Pf
+	for i := 0; i <= ths; i++ {
+		share, pf, err := m.decryptShare(ct, m.pp.signers[i])
+		assert.NoError(t, err)
+		assert.True(t, m.verifyDecryptShare(ct, i, share, pf))
+		assert.False(t, m.verifyDecryptShare(ct, (i+1)%n, share, pf))
+		signers = append(signers, i)
+		shares = append(shares, share)
+		pfs = append(pfs, pf)
+	}
+
+	pt, err := m.combineDecrypt(ct, signers, shares, pfs)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	// t shares are not enough
+	_, err = m.combineDecrypt(ct, signers[:ths], shares[:ths], pfs[:ths])
+	assert.ErrorIs(t, err, ErrCombineFailed)
+
+	// A wrong share is dropped, the extra valid one takes its place
+	extra, extraPf, _ := m.decryptShare(ct, m.pp.signers[ths+1])
+	badShares := append([]bls.G1Jac{}, shares...)
+	badShares[0] = shares[1]
+	pt, err = m.combineDecrypt(ct, append(signers, ths+1), append(badShares, extra), append(pfs, extraPf))
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	// A repeated index counts once, the extra share takes its place
+	dupSigners := append([]int{0}, append(signers, ths+1)...)
+	dupShares := append([]bls.G1Jac{shares[0]}, append(shares, extra)...)
+	dupPfs := append([]Pf{pfs[0]}, append(pfs, extraPf)...)
+	pt, err = m.combineDecrypt(ct, dupSigners, dupShares, dupPfs)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+	_, err = m.combineDecrypt(ct, dupSigners[:ths+1], dupShares[:ths+1], dupPfs[:ths+1])
+	assert.ErrorIs(t, err, ErrCombineFailed)
+
+	// Shares outside the subgroup are dropped
+	outside := nonSubgroupG1()
+	assert.False(t, m.verifyDecryptShare(ct, 0, *new(bls.G1Jac).FromAffine(&outside), pfs[0]))
+
+	// Shares are bound to the ciphertext
+	other, _ := m.encrypt(secret, []byte("vault/42"))
+	assert.False(t, m.verifyDecryptShare(other, 0, shares[0], pfs[0]))
+
+	// c0 can not be moved under another label or body without a new proof
+	relabeled, _ := decodeElGamal(ct, ctElGamal)
+	relabeled.label = []byte("vault/43")
+	_, _, err = m.decryptShare(relabeled.bytes(), m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	assert.False(t, m.verifyDecryptShare(relabeled.bytes(), 0, shares[0], pfs[0]))
+
+	lifted, _ := decodeElGamal(other, ctElGamal)
+	lifted.c0, lifted.c1 = relabeled.c0, relabeled.c1
+	_, _, err = m.decryptShare(lifted.bytes(), m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	// Tampered bodies are rejected before any share is made
+	tampered := append([]byte{}, ct...)
+	tampered[len(tampered)-1] ^= 1
+	_, _, err = m.decryptShare(tampered, m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = m.combineDecrypt(tampered, signers, shares, pfs)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, _, err = m.decryptShare(ct[:10], m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	// Decryption goes through the signer's policy
+	m.SetPolicy(NewAllowList(other))
+	_, _, err = m.decryptShare(ct, m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrNotAllowed)
+}
+
+func TestDecryptABLS(t *testing.T) {
+	secret := []byte("vault entry")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+
+	ct, err := m.encrypt(secret, nil)
+	assert.NoError(t, err)
+
+	var signers []int
+	var shares []bls.G1Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		share, pf, err := m.decryptShare(ct, m.pp.signers[i])
+		assert.NoError(t, err)
+		assert.True(t, m.verifyDecryptShare(ct, i, share, pf))
+		signers = append(signers, i)
+		shares = append(shares, share)
+		pfs = append(pfs, pf)
+	}
+
+	pt, err := m.combineDecrypt(ct, signers, shares, pfs)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	badPfs := append([]SigmaPf{}, pfs...)
+	badPfs[0] = pfs[1]
+	_, err = m.combineDecrypt(ct, signers, shares, badPfs)
+	assert.ErrorIs(t, err, ErrCombineFailed)
+
+	// A repeated index counts once
+	_, err = m.combineDecrypt(ct, append([]int{0}, signers[:ths]...), append([]bls.G1Jac{shares[0]}, shares[:ths]...), append([]SigmaPf{pfs[0]}, pfs[:ths]...))
+	assert.ErrorIs(t, err, ErrCombineFailed)
+	pt, err = m.combineDecrypt(ct, append([]int{0}, signers...), append([]bls.G1Jac{shares[0]}, shares...), append([]SigmaPf{pfs[0]}, pfs...))
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	outside := nonSubgroupG1()
+	assert.False(t, m.verifyDecryptShare(ct, 0, *new(bls.G1Jac).FromAffine(&outside), pfs[0]))
+
+	// c1 must carry the same exponent as c0
+	swapped, _ := decodeElGamal(ct, ctElGamalAdaptive)
+	swapped.c1 = swapped.c0
+	_, _, err = m.decryptShare(swapped.bytes(), m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	// Ciphertexts of one scheme are rejected by the other
+	_, _, err = m.decryptShare(append([]byte{ctElGamal}, ct[1:]...), m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
@@ -0,0 +1,141 @@
+package tss
+
+import (
+	"fmt"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+/*
+* Threshold PKG: the identity key H(id)^s is a threshold signature on id, so
+* users collect t+1 extraction shares, verify them like partial signatures and
+* interpolate their key. Identities are hashed under their own DST so that an
+* ordinary signature on a message is never an identity key.
+ */
+
+const ibeVersion = 1
+
+// IBE ciphertexts are encoded as version || U || V || W
+func encodeIBE(ct ibeCiphertext) []byte {
+	return append([]byte{ibeVersion}, ct.bytes()...)
+}
+
+func decodeIBE(data []byte) (ibeCiphertext, error) {
+	var ct ibeCiphertext
+	if len(data) < 1 {
+		return ct, ErrInvalidCiphertext
+	}
+	if data[0] != ibeVersion {
+		return ct, fmt.Errorf("%w: unsupported version %d", ErrInvalidCiphertext, data[0])
+	}
+	err := ct.setBytes(data[1:])
+	return ct, err
+}
+
+func (b *BLS) identityPoint(id []byte) (bls.G2Affine, error) {
+	return bls.HashToG2(id, append([]byte("IBE_ID_"), b.crs.dst...))
+}
+
+// Extraction share of a party with a DLEQ proof; the policy decides who may
+// extract and sees the identity.
+func (b *BLS) extractShare(id []byte, signer BLSParty) (bls.G2Jac, Pf, error) {
+	if err := checkPolicy(b.policy, id, signer.index); err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	idPoint, err := b.identityPoint(id)
+	if err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	share, pf := b.signPointDleq(nil, idPoint, signer)
+	return share, pf, nil
+}
+
+// Verifies the extraction shares, combines them and checks the identity key
+func (b *BLS) combineIdentityKey(id []byte, signers []int, shares []bls.G2Jac, pfs []Pf) (bls.G2Jac, error) {
+	idPoint, err := b.identityPoint(id)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	key := b.verifyCombineDleq(idPoint, signers, shares, pfs)
+	if !b.gverify(idPoint, key) {
+		return bls.G2Jac{}, ErrCombineFailed
+	}
+	return key, nil
+}
+
+func (b *BLS) ibeEncrypt(id []byte, msg []byte) ([]byte, error) {
+	idPoint, err := b.identityPoint(id)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := ibeEncrypt(b.pp.pk, b.crs.g1a, idPoint, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	return encodeIBE(ct), nil
+}
+
+func (b *BLS) ibeDecrypt(key bls.G2Jac, data []byte) ([]byte, error) {
+	ct, err := decodeIBE(data)
+	if err != nil {
+		return nil, err
+	}
+	return ibeDecrypt(*new(bls.G2Affine).FromJacobian(&key), b.crs.g1a, nil, ct)
+}
+
+func (b *ABLS) identityPoints(id []byte) (bls.G2Affine, bls.G2Affine, error) {
+	id0, err := bls.HashToG2(id, []byte("IBE_ID_DST0"))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	id1, err := bls.HashToG2(id, []byte("IBE_ID_DST1"))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	return id0, id1, nil
+}
+
+func (b *ABLS) extractShare(id []byte, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	if err := checkPolicy(b.policy, id, signer.index); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	id0, id1, err := b.identityPoints(id)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	share, pf := b.signPoints(nil, id0, id1, signer)
+	return share, pf, nil
+}
+
+// The combined key is H0(id)^s since r(0) = 0
+func (b *ABLS) combineIdentityKey(id []byte, signers []int, shares []bls.G2Jac, pfs []SigmaPf) (bls.G2Jac, error) {
+	id0, id1, err := b.identityPoints(id)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	key := b.verifyCombine(id0, id1, signers, shares, pfs)
+	if !b.gverify(id0, key) {
+		return bls.G2Jac{}, ErrCombineFailed
+	}
+	return key, nil
+}
+
+func (b *ABLS) ibeEncrypt(id []byte, msg []byte) ([]byte, error) {
+	id0, _, err := b.identityPoints(id)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := ibeEncrypt(b.pp.pk, b.crs.g1a, id0, nil, msg)
+	if err != nil {
+		return nil, err
+	}
+	return encodeIBE(ct), nil
+}
+
+func (b *ABLS) ibeDecrypt(key bls.G2Jac, data []byte) ([]byte, error) {
+	ct, err := decodeIBE(data)
+	if err != nil {
+		return nil, err
+	}
+	return ibeDecrypt(*new(bls.G2Affine).FromJacobian(&key), b.crs.g1a, nil, ct)
+}
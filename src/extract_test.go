@@ -0,0 +1,110 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractBLS(t *testing.T) {
+	id := []byte("alice@example.com")
+	secret := []byte("for alice only")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+
+	ct, err := m.ibeEncrypt(id, secret)
+	assert.NoError(t, err)
+
+	idPoint, _ := m.identityPoint(id)
+	var signers []int
+	var shares []bls.G2Jac
+	var pfs []Pf
+	for i := 0; i <= ths; i++ {
+		share, pf, err := m.extractShare(id, m.pp.signers[i])
+		assert.NoError(t, err)
+		assert.True(t, m.pVerifyDleq(idPoint, share, i, pf))
+		signers = append(signers, i)
+		shares = append(shares, share)
+		pfs = append(pfs, pf)
+	}
+
+	// t shares are not enough
+	_, err = m.combineIdentityKey(id, signers[:ths], shares[:ths], pfs[:ths])
+	assert.ErrorIs(t, err, ErrCombineFailed)
+
+	// A share with a bad proof is dropped
+	badPfs := append([]Pf{}, pfs...)
+	badPfs[0] = pfs[1]
+	_, err = m.combineIdentityKey(id, signers, shares, badPfs)
+	assert.ErrorIs(t, err, ErrCombineFailed)
+
+	key, err := m.combineIdentityKey(id, signers, shares, pfs)
+	assert.NoError(t, err)
+	pt, err := m.ibeDecrypt(key, ct)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	// Another identity's key does not decrypt
+	bob := []byte("bob@example.com")
+	shares = shares[:0]
+	pfs = pfs[:0]
+	for i := 0; i <= ths; i++ {
+		share, pf, _ := m.extractShare(bob, m.pp.signers[i])
+		shares = append(shares, share)
+		pfs = append(pfs, pf)
+	}
+	bobKey, err := m.combineIdentityKey(bob, signers, shares, pfs)
+	assert.NoError(t, err)
+	_, err = m.ibeDecrypt(bobKey, ct)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	// A signature on the identity as a message is not the identity key
+	msgRo, _ := m.hashMsg(id)
+	var sigs []bls.G2Jac
+	for i := 0; i <= ths; i++ {
+		sigma, _ := m.psign(id, m.pp.signers[i])
+		sigs = append(sigs, sigma)
+	}
+	_, err = m.ibeDecrypt(m.verifyCombine(msgRo, signers, sigs), ct)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	// Extraction goes through the signer's policy
+	m.SetPolicy(NewAllowList(bob))
+	_, _, err = m.extractShare(id, m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrNotAllowed)
+}
+
+func TestExtractABLS(t *testing.T) {
+	id := []byte("alice@example.com")
+	secret := []byte("for alice only")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+
+	ct, err := m.ibeEncrypt(id, secret)
+	assert.NoError(t, err)
+
+	var signers []int
+	var shares []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		share, pf, err := m.extractShare(id, m.pp.signers[i])
+		assert.NoError(t, err)
+		signers = append(signers, i)
+		shares = append(shares, share)
+		pfs = append(pfs, pf)
+	}
+	key, err := m.combineIdentityKey(id, signers, shares, pfs)
+	assert.NoError(t, err)
+	pt, err := m.ibeDecrypt(key, ct)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	ct[0] = ibeVersion + 1
+	_, err = m.ibeDecrypt(key, ct)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
@@ -0,0 +1,119 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+/*
+* Fixed-base tables for the CRS generators. For a window of w bits the table of
+* P holds d 2^(wj) P for every window j and digit 1 <= d < 2^w, so that s P is
+* one mixed addition per non-zero digit and no doublings. A table takes
+* ceil(255/w) (2^w - 1) affine points of 96 bytes:
+*
+*	w = 4:  64 x 15  ~  90 KiB
+*	w = 6:  43 x 63  ~ 254 KiB
+*	w = 8:  32 x 255 ~ 765 KiB
+*
+* Windows are limited to 16 bits, where a table already takes ~96 MiB.
+* Several bases share one accumulator, so g1^s h1^r v1^u costs 3 ceil(255/w)
+* additions. Like the gnark scalar multiplication it replaces, the lookup is
+* not constant time.
+ */
+
+const (
+	DefaultFixedBaseWindow = 6
+	MaxFixedBaseWindow     = 16
+)
+
+var ErrInvalidWindow = errors.New("fixed-base window out of range")
+
+func validWindow(w int) error {
+	if w < 1 || w > MaxFixedBaseWindow {
+		return fmt.Errorf("%w: %d bits, want 1 to %d", ErrInvalidWindow, w, MaxFixedBaseWindow)
+	}
+	return nil
+}
+
+const frBits = 255
+
+type fixedBaseTable struct {
+	w     int
+	table [][]bls.G1Affine
+}
+
+func newFixedBaseTable(p bls.G1Affine, w int) *fixedBaseTable {
+	windows := (frBits + w - 1) / w
+	size := 1<<w - 1
+
+	points := make([]bls.G1Jac, windows*size)
+	var base bls.G1Jac
+	base.FromAffine(&p)
+	for j := 0; j < windows; j++ {
+		row := points[j*size : (j+1)*size]
+		row[0] = base
+		for d := 1; d < size; d++ {
+			row[d].Set(&row[d-1]).AddAssign(&base)
+		}
+		// 2^w base for the next window
+		base.Set(&row[size-1]).AddAssign(&row[0])
+	}
+
+	affine := bls.BatchJacobianToAffineG1(points)
+	table := make([][]bls.G1Affine, windows)
+	for j := range table {
+		table[j] = affine[j*size : (j+1)*size]
+	}
+	return &fixedBaseTable{w: w, table: table}
+}
+
+// acc += s P
+func (t *fixedBaseTable) mulAdd(acc *bls.G1Jac, s *fr.Element) {
+	limbs := s.Bits()
+	mask := uint64(1)<<t.w - 1
+	for j := range t.table {
+		pos := j * t.w
+		digit := limbs[pos/64] >> (pos % 64)
+		if pos%64+t.w > 64 && pos/64+1 < len(limbs) {
+			digit |= limbs[pos/64+1] << (64 - pos%64)
+		}
+		if digit &= mask; digit != 0 {
+			acc.AddMixed(&t.table[j][digit-1])
+		}
+	}
+}
+
+// Tables for a list of bases; nil for a CRS without tables
+type fixedBases []*fixedBaseTable
+
+func newFixedBases(w int, bases ...bls.G1Affine) fixedBases {
+	fb := make(fixedBases, len(bases))
+	for i := range bases {
+		fb[i] = newFixedBaseTable(bases[i], w)
+	}
+	return fb
+}
+
+// sum_i scalars_i bases_i, falling back to an MSM without tables
+func (fb fixedBases) multiExp(bases []bls.G1Affine, scalars ...fr.Element) bls.G1Jac {
+	var acc bls.G1Jac
+	if fb == nil {
+		if len(bases) == 1 {
+			acc.FromAffine(&bases[0])
+			return *acc.ScalarMultiplication(&acc, scalars[0].BigInt(&big.Int{}))
+		}
+		acc.MultiExp(bases[:len(scalars)], scalars, ecc.MultiExpConfig{})
+		return acc
+	}
+	acc.X.SetOne()
+	acc.Y.SetOne()
+	for i := range scalars {
+		fb[i].mulAdd(&acc, &scalars[i])
+	}
+	return acc
+}
@@ -0,0 +1,134 @@
+package tss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedBaseTable(t *testing.T) {
+	_, _, p, _ := bls.Generators()
+	var s fr.Element
+	s.SetRandom()
+	var q bls.G1Affine
+	q.ScalarMultiplication(&p, s.BigInt(&big.Int{}))
+	bases := []bls.G1Affine{p, q}
+
+	var minusOne, allOnes fr.Element
+	minusOne.SetOne().Neg(&minusOne)
+	allOnes.SetBigInt(new(big.Int).Lsh(big.NewInt(1), 254))
+	allOnes.Sub(&allOnes, new(fr.Element).SetOne())
+
+	scalars := [][2]fr.Element{
+		{fr.Element{}, fr.Element{}},
+		{fr.One(), fr.Element{}},
+		{minusOne, allOnes},
+		{allOnes, minusOne},
+	}
+	for i := 0; i < 4; i++ {
+		var a, b fr.Element
+		a.SetRandom()
+		b.SetRandom()
+		scalars = append(scalars, [2]fr.Element{a, b})
+	}
+
+	for _, w := range []int{1, 2, 5, 6, 7, 8, 11} {
+		fb := newFixedBases(w, bases...)
+		for _, sc := range scalars {
+			var want bls.G1Jac
+			want.MultiExp(bases, sc[:], ecc.MultiExpConfig{})
+			got := fb.multiExp(bases, sc[:]...)
+			assert.True(t, got.Equal(&want), "w=%d", w)
+
+			// A single scalar only uses the first table
+			want.ScalarMultiplication(new(bls.G1Jac).FromAffine(&p), sc[0].BigInt(&big.Int{}))
+			got = fb.multiExp(bases, sc[0])
+			assert.True(t, got.Equal(&want), "w=%d", w)
+		}
+	}
+
+	// Without tables the fallback computes the same
+	var nofb fixedBases
+	got := nofb.multiExp(bases, scalars[4][:]...)
+	want := newFixedBases(4, bases...).multiExp(bases, scalars[4][:]...)
+	assert.True(t, got.Equal(&want))
+}
+
+// Keys and proofs do not depend on the window
+func TestFixedBaseWindow(t *testing.T) {
+	msg := []byte("hello world")
+	n := 1 << 3
+
+	crs := GenABLSCRS(n)
+	m := NewABLS(n, n/2, crs)
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+	sigma, pf, _ := m.pSign(msg, m.pp.signers[1])
+	for _, w := range []int{0, 3, 8} {
+		other := m
+		setWindowABLS(&other.crs, w)
+		assert.True(t, other.pVerify(ro0Msg, ro1Msg, sigma, 1, pf), "w=%d", w)
+		s, p, _ := other.pSign(msg, other.pp.signers[2])
+		assert.True(t, m.pVerify(ro0Msg, ro1Msg, s, 2, p), "w=%d", w)
+		g1 := other.commitG1(fr.One())
+		assert.True(t, g1.Equal(&crs.g1))
+	}
+
+	bcrs := GenBLSCRS(n)
+	setWindowBLS(&bcrs, 0)
+	b0 := NewBLS(n, n/2, bcrs)
+	roMsg, _ := b0.hashMsg(msg)
+	bsigma, bpf, _ := b0.pSignDleq(msg, b0.pp.signers[1])
+	for _, w := range []int{3, 8} {
+		other := b0
+		setWindowBLS(&other.crs, w)
+		assert.True(t, other.pVerifyDleq(roMsg, bsigma, 1, bpf), "w=%d", w)
+		pk := other.mulG1(other.pp.signers[1].sKey)
+		assert.True(t, pk.Equal(&b0.pp.signers[1].pKey))
+	}
+}
+
+func TestFixedBaseWindowBounds(t *testing.T) {
+	n := 1 << 2
+	acrs := GenABLSCRS(n)
+	bcrs := GenBLSCRS(n)
+	for _, w := range []int{-1, 0, MaxFixedBaseWindow + 1} {
+		assert.ErrorIs(t, acrs.SetFixedBaseWindow(w), ErrInvalidWindow)
+		assert.ErrorIs(t, bcrs.SetFixedBaseWindow(w), ErrInvalidWindow)
+	}
+	// The tables are kept on error
+	assert.Equal(t, DefaultFixedBaseWindow, acrs.fb[0].w)
+	assert.Equal(t, DefaultFixedBaseWindow, bcrs.fb[0].w)
+
+	assert.NoError(t, acrs.SetFixedBaseWindow(1))
+	assert.NoError(t, bcrs.SetFixedBaseWindow(2))
+	assert.Equal(t, 1, acrs.fb[0].w)
+	assert.Equal(t, 2, bcrs.fb[0].w)
+}
+
+// Windows the scheme benchmarks run with, 0 for no tables
+var benchWindows = []int{0, 4, DefaultFixedBaseWindow, 8}
+
+// Window 0 drops the tables, so benchmarks can compare against the fallback
+func setWindowABLS(crs *ABLSCRS, w int) {
+	if w == 0 {
+		crs.fb = nil
+		return
+	}
+	if err := crs.SetFixedBaseWindow(w); err != nil {
+		panic(err)
+	}
+}
+
+func setWindowBLS(crs *BLSCRS, w int) {
+	if w == 0 {
+		crs.fb = nil
+		return
+	}
+	if err := crs.SetFixedBaseWindow(w); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,163 @@
+package tss
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+var (
+	ErrDecrypt           = errors.New("ciphertext does not decrypt under this key")
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+/*
+* Boneh-Franklin IBE with the Fujisaki-Okamoto transform (FullIdent), in the
+* same groups as the signatures: the master public key is pk = g1^s in G1, an
+* identity is a point Q = H(id) in G2, and the identity key is Q^s, i.e. the
+* threshold signature on id.
+*
+*	sigma <- random, r = H3(sigma, m)
+*	U = g1^r, V = sigma xor H2(e(pk, Q)^r), W = m xor H4(sigma)
+*
+* Decryption recovers e(pk, Q)^r = e(U, Q^s) and rejects unless U = g1^H3(sigma, m).
+ */
+
+type ibeCiphertext struct {
+	U bls.G1Affine
+	V [32]byte
+	W []byte
+}
+
+const ibeHeaderSize = bls.SizeOfG1AffineCompressed + 32
+
+// Encoded as U (48 bytes) || V (32 bytes) || W
+func (ct *ibeCiphertext) bytes() []byte {
+	uBytes := ct.U.Bytes()
+	buf := make([]byte, 0, ibeHeaderSize+len(ct.W))
+	buf = append(buf, uBytes[:]...)
+	buf = append(buf, ct.V[:]...)
+	return append(buf, ct.W...)
+}
+
+func (ct *ibeCiphertext) setBytes(data []byte) error {
+	if len(data) < ibeHeaderSize {
+		return ErrInvalidCiphertext
+	}
+	if _, err := ct.U.SetBytes(data[:bls.SizeOfG1AffineCompressed]); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	copy(ct.V[:], data[bls.SizeOfG1AffineCompressed:ibeHeaderSize])
+	ct.W = append([]byte{}, data[ibeHeaderSize:]...)
+	return nil
+}
+
+func ibeH2(gid *bls.GT) [32]byte {
+	gidBytes := gid.Bytes()
+	h := sha256.New()
+	h.Write([]byte("TSS-IBE-H2"))
+	h.Write(gidBytes[:])
+
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// ad is length-prefixed so that it can not run into sigma
+func ibeH3(sigma []byte, ad []byte, msg []byte) (fr.Element, error) {
+	buf := make([]byte, 0, 8+len(ad)+len(sigma)+len(msg))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(ad)))
+	buf = append(buf, ad...)
+	buf = append(buf, sigma...)
+	buf = append(buf, msg...)
+	r, err := fr.Hash(buf, []byte("TSS-IBE-H3"), 1)
+	if err != nil {
+		return fr.Element{}, err
+	}
+	return r[0], nil
+}
+
+// ibeH4 expands sigma into a pad of length n
+func ibeH4(sigma []byte, n int) []byte {
+	pad := make([]byte, 0, n+sha256.Size)
+	for ctr := uint32(0); len(pad) < n; ctr++ {
+		h := sha256.New()
+		h.Write([]byte("TSS-IBE-H4"))
+		h.Write(sigma)
+		h.Write(binary.BigEndian.AppendUint32(nil, ctr))
+		pad = h.Sum(pad)
+	}
+	return pad[:n]
+}
+
+func xorBytes(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+// Encrypts msg to the identity point id under the master key pk = g1^s. The
+// associated data ad is not encrypted but bound into r, decryption fails unless
+// the same ad is given.
+func ibeEncrypt(pk bls.G1Affine, g1 bls.G1Affine, id bls.G2Affine, ad []byte, msg []byte) (ibeCiphertext, error) {
+	var ct ibeCiphertext
+	sigma := make([]byte, 32)
+	if _, err := rand.Read(sigma); err != nil {
+		return ct, err
+	}
+	r, err := ibeH3(sigma, ad, msg)
+	if err != nil {
+		return ct, err
+	}
+	rInt := r.BigInt(&big.Int{})
+
+	var pkR bls.G1Affine
+	pkR.ScalarMultiplication(&pk, rInt)
+	gid, err := bls.Pair([]bls.G1Affine{pkR}, []bls.G2Affine{id})
+	if err != nil {
+		return ct, err
+	}
+
+	ct.U.ScalarMultiplication(&g1, rInt)
+	h2 := ibeH2(&gid)
+	xorBytes(ct.V[:], sigma, h2[:])
+	ct.W = make([]byte, len(msg))
+	xorBytes(ct.W, msg, ibeH4(sigma, len(msg)))
+	return ct, nil
+}
+
+// Decrypts with the identity key Q^s
+func ibeDecrypt(key bls.G2Affine, g1 bls.G1Affine, ad []byte, ct ibeCiphertext) ([]byte, error) {
+	if ct.U.IsInfinity() || !ct.U.IsInSubGroup() || !key.IsInSubGroup() {
+		return nil, ErrDecrypt
+	}
+	gid, err := bls.Pair([]bls.G1Affine{ct.U}, []bls.G2Affine{key})
+	if err != nil {
+		return nil, err
+	}
+
+	sigma := make([]byte, 32)
+	h2 := ibeH2(&gid)
+	xorBytes(sigma, ct.V[:], h2[:])
+	msg := make([]byte, len(ct.W))
+	xorBytes(msg, ct.W, ibeH4(sigma, len(ct.W)))
+
+	r, err := ibeH3(sigma, ad, msg)
+	if err != nil {
+		return nil, err
+	}
+	var u bls.G1Affine
+	u.ScalarMultiplication(&g1, r.BigInt(&big.Int{}))
+	uBytes, ctBytes := u.Bytes(), ct.U.Bytes()
+	if subtle.ConstantTimeCompare(uBytes[:], ctBytes[:]) != 1 {
+		return nil, ErrDecrypt
+	}
+	return msg, nil
+}
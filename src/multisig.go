@@ -0,0 +1,149 @@
+package tss
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+/*
+* n-of-n BLS multisignatures for a fixed group of independently keyed parties.
+* Two ways of stopping rogue keys are offered:
+*
+*	MultiSigPoP: every key comes with a proof of possession and the
+*	aggregate key is the plain sum of the keys.
+*	MultiSigBDN: key i is weighted by a_i = H(pk_1, ..., pk_n)_i (Boneh,
+*	Drijvers and Neven), so no key can be chosen to cancel the others.
+*
+* The group is held in a BLS instance whose pKeys are the party keys and whose
+* pk is the aggregate key, so partial and final signatures verify with pverify
+* and gverify.
+ */
+
+type MultiSigMode int
+
+const (
+	MultiSigPoP MultiSigMode = iota
+	MultiSigBDN
+)
+
+type MultiSig struct {
+	mode   MultiSigMode
+	coeffs []fr.Element
+	b      BLS
+}
+
+// GenMultiSigParty draws an independent key for the party at position index
+func GenMultiSigParty(crs BLSCRS, index int) BLSParty {
+	var sKey fr.Element
+	sKey.SetRandom()
+	return BLSParty{
+		sKey:  sKey,
+		pKey:  *new(bls.G1Jac).ScalarMultiplication(&crs.g1, sKey.BigInt(&big.Int{})),
+		index: index,
+	}
+}
+
+// GenMultiSigPop proves possession of the key of a party, as NewMultiSig
+// expects in MultiSigPoP mode
+func GenMultiSigPop(crs BLSCRS, signer BLSParty) (bls.G2Jac, error) {
+	b := BLS{crs: crs}
+	return b.popProve(signer)
+}
+
+// Hash-derived key coefficients, bound to the whole ordered key set
+func bdnCoeffs(pKeys []bls.G1Affine) ([]fr.Element, error) {
+	buf := make([]byte, 0, len(pKeys)*bls.SizeOfG1AffineCompressed)
+	for i := range pKeys {
+		pkBytes := pKeys[i].Bytes()
+		buf = append(buf, pkBytes[:]...)
+	}
+	return fr.Hash(buf, []byte("BLS_MULTISIG_BDN_"), len(pKeys))
+}
+
+// NewMultiSig sets up the group from the parties' keys; pops are only needed
+// in MultiSigPoP mode
+func NewMultiSig(crs BLSCRS, pKeys []bls.G1Affine, pops []bls.G2Jac, mode MultiSigMode) (MultiSig, error) {
+	n := len(pKeys)
+	m := MultiSig{
+		mode: mode,
+		b: BLS{
+			n:   n,
+			t:   n - 1,
+			crs: crs,
+			pp:  BLSParams{pKeys: append([]bls.G1Affine{}, pKeys...)},
+		},
+	}
+
+	switch mode {
+	case MultiSigPoP:
+		apk, err := m.b.aggregateKeys(pKeys, pops)
+		if err != nil {
+			return MultiSig{}, err
+		}
+		m.b.pp.pk = apk
+	case MultiSigBDN:
+		coeffs, err := bdnCoeffs(pKeys)
+		if err != nil {
+			return MultiSig{}, err
+		}
+		m.coeffs = coeffs
+		m.b.pp.pk.MultiExp(pKeys, coeffs, ecc.MultiExpConfig{})
+	default:
+		return MultiSig{}, ErrUnknownScheme
+	}
+	return m, nil
+}
+
+func (m *MultiSig) SetPolicy(p SignPolicy) {
+	m.b.SetPolicy(p)
+}
+
+func (m *MultiSig) aggregateKey() bls.G1Affine {
+	return m.b.pp.pk
+}
+
+// Plain BLS signature of a party under its own key
+func (m *MultiSig) psign(msg Message, signer BLSParty) (bls.G2Jac, error) {
+	return m.b.psign(msg, signer)
+}
+
+// Verifies the signatures of all n parties, given in key order, and aggregates them
+func (m *MultiSig) combine(msg Message, sigmas []bls.G2Jac) (bls.G2Jac, error) {
+	if len(sigmas) != m.b.n {
+		return bls.G2Jac{}, ErrCombineFailed
+	}
+	roMsg, err := m.b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	for i := range sigmas {
+		if !m.b.pverify(roMsg, sigmas[i], m.b.pp.pKeys[i]) {
+			return bls.G2Jac{}, ErrCombineFailed
+		}
+	}
+
+	var sigma bls.G2Jac
+	if m.mode == MultiSigBDN {
+		sigmasAf := make([]bls.G2Affine, len(sigmas))
+		for i := range sigmas {
+			sigmasAf[i].FromJacobian(&sigmas[i])
+		}
+		sigma.MultiExp(sigmasAf, m.coeffs, ecc.MultiExpConfig{})
+		return sigma, nil
+	}
+	for i := range sigmas {
+		sigma.AddAssign(&sigmas[i])
+	}
+	return sigma, nil
+}
+
+func (m *MultiSig) verify(msg Message, sigma bls.G2Jac) bool {
+	roMsg, err := m.b.hashMsg(msg)
+	if err != nil {
+		return false
+	}
+	return m.b.gverify(roMsg, sigma)
+}
@@ -0,0 +1,99 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func genMultiSigGroup(crs BLSCRS, n int) ([]BLSParty, []bls.G1Affine) {
+	parties := make([]BLSParty, n)
+	pKeys := make([]bls.G1Affine, n)
+	for i := range parties {
+		parties[i] = GenMultiSigParty(crs, i)
+		pKeys[i].FromJacobian(&parties[i].pKey)
+	}
+	return parties, pKeys
+}
+
+func TestMultiSig(t *testing.T) {
+	msg := []byte("hello world")
+	n := 5
+	crs := GenBLSCRS(n)
+	parties, pKeys := genMultiSigGroup(crs, n)
+
+	pops := make([]bls.G2Jac, n)
+	for i := range parties {
+		var err error
+		pops[i], err = GenMultiSigPop(crs, parties[i])
+		assert.NoError(t, err)
+	}
+
+	for _, mode := range []MultiSigMode{MultiSigPoP, MultiSigBDN} {
+		m, err := NewMultiSig(crs, pKeys, pops, mode)
+		assert.NoError(t, err)
+
+		sigmas := make([]bls.G2Jac, n)
+		for i := range parties {
+			sigmas[i], _ = m.psign(msg, parties[i])
+		}
+		sigma, err := m.combine(msg, sigmas)
+		assert.NoError(t, err)
+		assert.True(t, m.verify(msg, sigma))
+		assert.False(t, m.verify([]byte("other"), sigma))
+
+		// n-of-n: every party has to sign
+		_, err = m.combine(msg, sigmas[1:])
+		assert.ErrorIs(t, err, ErrCombineFailed)
+		sigmas[0] = sigmas[1]
+		_, err = m.combine(msg, sigmas)
+		assert.ErrorIs(t, err, ErrCombineFailed)
+	}
+}
+
+func TestMultiSigRogueKey(t *testing.T) {
+	msg := []byte("hello world")
+	n := 3
+	crs := GenBLSCRS(n)
+	_, pKeys := genMultiSigGroup(crs, n)
+
+	// The attacker picks pk_a = g1^x - sum of the honest keys, so the plain
+	// aggregate is g1^x and x alone signs for the group
+	attacker := GenMultiSigParty(crs, n)
+	var rogue bls.G1Jac
+	rogue.Set(&attacker.pKey)
+	for i := range pKeys {
+		rogue.SubAssign(new(bls.G1Jac).FromAffine(&pKeys[i]))
+	}
+	keys := append(pKeys, *new(bls.G1Affine).FromJacobian(&rogue))
+
+	m := BLS{crs: crs}
+	roMsg, _ := m.hashMsg(msg)
+	forged := m.signPoint(roMsg, attacker)
+
+	var plain bls.G1Jac
+	for i := range keys {
+		plain.AddMixed(&keys[i])
+	}
+	assert.True(t, m.pverify(roMsg, forged, *new(bls.G1Affine).FromJacobian(&plain)))
+
+	// Without a valid proof of possession the key is refused
+	pops := make([]bls.G2Jac, n+1)
+	for i := range pops {
+		pops[i] = forged
+	}
+	_, err := NewMultiSig(crs, keys, pops, MultiSigPoP)
+	assert.ErrorIs(t, err, ErrInvalidPoP)
+
+	// and a proof for the attacker's real key does not carry over
+	pops[n], err = GenMultiSigPop(crs, attacker)
+	assert.NoError(t, err)
+	_, err = NewMultiSig(crs, keys, pops, MultiSigPoP)
+	assert.ErrorIs(t, err, ErrInvalidPoP)
+
+	// With hash-derived coefficients the forgery does not verify
+	ms, err := NewMultiSig(crs, keys, nil, MultiSigBDN)
+	assert.NoError(t, err)
+	assert.False(t, ms.verify(msg, forged))
+}
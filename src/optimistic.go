@@ -0,0 +1,79 @@
+package tss
+
+import (
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+/*
+* Optimistic combination: interpolate the first t+1 partials without looking
+* at them and check the result with a single gverify. Only when that fails are
+* the partials verified, to find the bad ones and replace them with further
+* partials. Since every partial is verified against the registered key of its
+* signer in that case, the blame is correct with up to t malicious partials.
+ */
+
+// The first t+1 partials can only be interpolated if their indices are valid and distinct
+func firstQuorum(n, t int, signers []int) bool {
+	return len(signers) > t && validSigners(n, signers[:t+1]) == nil
+}
+
+// Returns the signature and the signers it was interpolated from. When the
+// optimistic check fails, the partials that are invalid, repeated or out of
+// range are listed in Rejected; RejectBadProof ones blame their signers.
+func (b *BLS) optimisticCombine(roMsg bls.G2Affine, signers []int, sigmas []bls.G2Jac) (CombineResult, error) {
+	if res, ok := b.optimisticFirst(roMsg, signers, sigmas); ok {
+		return res, nil
+	}
+	good, rejected := batchSelect(b.n, b.t+1, signers, b.pairingChecker(roMsg, signers, sigmas))
+	return b.positionsResult(good, rejected, signers, sigmas)
+}
+
+// Same for DLEQ partials; the proofs are only looked at when the optimistic check fails
+func (b *BLS) optimisticCombineDleq(roMsg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []DleqCmtPf) (CombineResult, error) {
+	if res, ok := b.optimisticFirst(roMsg, signers, sigmas); ok {
+		return res, nil
+	}
+	good, rejected := batchSelect(b.n, b.t+1, signers, b.dleqChecker(roMsg, nil, signers, sigmas, pfs))
+	return b.positionsResult(good, rejected, signers, sigmas)
+}
+
+func (b *BLS) optimisticFirst(roMsg bls.G2Affine, signers []int, sigmas []bls.G2Jac) (CombineResult, bool) {
+	if !firstQuorum(b.n, b.t, signers) {
+		return CombineResult{}, false
+	}
+	quorum := GetRange(0, b.t+1)
+	sigma := b.combinePositions(quorum, signers, sigmas)
+	if !b.gverify(roMsg, sigma) {
+		return CombineResult{}, false
+	}
+	return CombineResult{Sigma: sigma, Signers: append([]int{}, signers[:b.t+1]...)}, true
+}
+
+// Combines the partials picked by batchSelect
+func (b *BLS) positionsResult(good []int, rejected []Rejection, signers []int, sigmas []bls.G2Jac) (CombineResult, error) {
+	used := make([]int, len(good))
+	sigs := make([]bls.G2Affine, len(good))
+	for i, pos := range good {
+		used[i] = signers[pos]
+		sigs[i].FromJacobian(&sigmas[pos])
+	}
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
+
+func (b *ABLS) optimisticCombine(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) (CombineResult, error) {
+	if firstQuorum(b.n, b.t, signers) {
+		sigmasAf := make([]bls.G2Affine, b.t+1)
+		for i := range sigmasAf {
+			sigmasAf[i].FromJacobian(&sigmas[i])
+		}
+		sigma := b.combine(signers, sigmasAf)
+		if b.gverify(ro0Msg, sigma) {
+			return CombineResult{Sigma: sigma, Signers: append([]int{}, signers[:b.t+1]...)}, nil
+		}
+	}
+
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(pos int, _ *bls.G2Affine) bool {
+		return b.pVerify(ro0Msg, ro1Msg, sigmas[pos], signers[pos], pfs[pos])
+	})
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
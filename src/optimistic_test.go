@@ -0,0 +1,121 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimisticBLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n/2 - 1
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []DleqCmtPf
+	for i := 0; i < n; i++ {
+		sigma, pf, _ := m.pSignDleqCmt(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+
+	res, err := m.optimisticCombine(roMsg, signers, sigmas)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	assert.Nil(t, res.Rejected)
+
+	// A repeated signer among the first t+1 is skipped, not blamed
+	dup := append([]int{0}, signers...)
+	dupSigmas := append([]bls.G2Jac{sigmas[0]}, sigmas...)
+	res, err = m.optimisticCombine(roMsg, dup, dupSigmas)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	assert.Equal(t, []Rejection{{Signer: 0, Position: 1, Reason: RejectDuplicate}}, res.Rejected)
+	res, err = m.optimisticCombineDleq(roMsg, dup, dupSigmas, append([]DleqCmtPf{pfs[0]}, pfs...))
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	_, err = m.optimisticCombine(roMsg, dup[:ths+1], dupSigmas[:ths+1])
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+
+	// t malicious partials among the first t+1
+	want := GetRange(1, ths+1)
+	for _, i := range want {
+		sigmas[i].AddAssign(&m.crs.g2)
+	}
+
+	res, err = m.optimisticCombine(roMsg, signers, sigmas)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	assert.Equal(t, want, blamedSigners(res.Rejected))
+
+	res, err = m.optimisticCombineDleq(roMsg, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	assert.Equal(t, want, blamedSigners(res.Rejected))
+
+	// Not enough honest partials left
+	res, err = m.optimisticCombine(roMsg, signers[:ths+1], sigmas[:ths+1])
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+	assert.True(t, res.Sigma.Equal(&bls.G2Jac{}))
+	assert.Equal(t, want, blamedSigners(res.Rejected))
+}
+
+func TestOptimisticABLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 4
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i < n; i++ {
+		sigma, pf, _ := m.pSign(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+
+	res, err := m.optimisticCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, res.Sigma))
+	assert.Nil(t, res.Rejected)
+
+	// A repeated signer is skipped and reported
+	dup := append([]int{1}, signers...)
+	dupSigmas := append([]bls.G2Jac{sigmas[1]}, sigmas...)
+	dupPfs := append([]SigmaPf{pfs[1]}, pfs...)
+	res, err = m.optimisticCombine(ro0Msg, ro1Msg, dup, dupSigmas, dupPfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, res.Sigma))
+	assert.Equal(t, []Rejection{{Signer: 1, Position: 2, Reason: RejectDuplicate}}, res.Rejected)
+	_, err = m.optimisticCombine(ro0Msg, ro1Msg, dup[:ths+1], dupSigmas[:ths+1], dupPfs[:ths+1])
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+
+	sigmas[0], sigmas[5] = sigmas[5], sigmas[0]
+	signers[3] = n
+	res, err = m.optimisticCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, res.Sigma))
+	assert.Equal(t, []int{0, 5}, blamedSigners(res.Rejected))
+	assert.Equal(t, []Rejection{{Signer: n, Position: 3, Reason: RejectUnknownIndex}}, res.RejectedFor(RejectUnknownIndex))
+}
+
+// Signers whose partials failed verification
+func blamedSigners(rejected []Rejection) []int {
+	var blamed []int
+	for _, rej := range rejected {
+		if rej.Reason == RejectBadProof {
+			blamed = append(blamed, rej.Signer)
+		}
+	}
+	return blamed
+}
@@ -0,0 +1,165 @@
+package tss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Every encoded payload starts with this tag so that it can not be confused
+// with messages framed by other protocols
+const payloadTag = "TSS-PAYLOAD"
+
+var ErrInvalidPayload = errors.New("invalid payload encoding")
+
+// Payload is the structured message threshold signers sign. Its encoding is
+// canonical: two payloads encode to the same bytes only if all their fields
+// are equal.
+type Payload struct {
+	Domain  string
+	Version uint16
+	Epoch   uint64
+	Round   uint64
+	Body    []byte
+}
+
+// Encode serializes the payload as
+// tag || len(domain) || domain || version || epoch || round || len(body) || body
+// with every integer in big-endian and every length as a uint32.
+func (p Payload) Encode() (Message, error) {
+	if uint64(len(p.Domain)) > math.MaxUint32 || uint64(len(p.Body)) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: field longer than 2^32-1 bytes", ErrInvalidPayload)
+	}
+
+	buf := make([]byte, 0, len(payloadTag)+4+len(p.Domain)+2+8+8+4+len(p.Body))
+	buf = append(buf, payloadTag...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.Domain)))
+	buf = append(buf, p.Domain...)
+	buf = binary.BigEndian.AppendUint16(buf, p.Version)
+	buf = binary.BigEndian.AppendUint64(buf, p.Epoch)
+	buf = binary.BigEndian.AppendUint64(buf, p.Round)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(p.Body)))
+	buf = append(buf, p.Body...)
+	return buf, nil
+}
+
+// DecodePayload parses an encoded payload and rejects trailing bytes
+func DecodePayload(msg Message) (Payload, error) {
+	var p Payload
+	if !bytes.HasPrefix(msg, []byte(payloadTag)) {
+		return p, ErrInvalidPayload
+	}
+	rest := msg[len(payloadTag):]
+
+	domain, rest, ok := readPrefixed(rest)
+	if !ok || len(rest) < 2+8+8 {
+		return p, ErrInvalidPayload
+	}
+	p.Domain = string(domain)
+	p.Version = binary.BigEndian.Uint16(rest)
+	p.Epoch = binary.BigEndian.Uint64(rest[2:])
+	p.Round = binary.BigEndian.Uint64(rest[10:])
+
+	body, rest, ok := readPrefixed(rest[18:])
+	if !ok || len(rest) != 0 {
+		return p, ErrInvalidPayload
+	}
+	p.Body = append([]byte{}, body...)
+	return p, nil
+}
+
+func readPrefixed(buf []byte) ([]byte, []byte, bool) {
+	if len(buf) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(buf)
+	buf = buf[4:]
+	if uint64(len(buf)) < uint64(n) {
+		return nil, nil, false
+	}
+	return buf[:n], buf[n:], true
+}
+
+func (b *ABLS) pSignPayload(p Payload, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	msg, err := p.Encode()
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	return b.pSign(msg, signer)
+}
+
+func (b *ABLS) hashPayload(p Payload) (bls.G2Affine, bls.G2Affine, error) {
+	msg, err := p.Encode()
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	return b.hashMsg(msg)
+}
+
+func (b *ABLS) verifyCombinePayload(p Payload, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) (bls.G2Jac, error) {
+	ro0Msg, ro1Msg, err := b.hashPayload(p)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.verifyCombine(ro0Msg, ro1Msg, signers, sigmas, pfs), nil
+}
+
+func (b *ABLS) gverifyPayload(p Payload, sigma bls.G2Jac) bool {
+	ro0Msg, _, err := b.hashPayload(p)
+	if err != nil {
+		return false
+	}
+	return b.gverify(ro0Msg, sigma)
+}
+
+func (b *BLS) psignPayload(p Payload, signer BLSParty) (bls.G2Jac, error) {
+	msg, err := p.Encode()
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.psign(msg, signer)
+}
+
+func (b *BLS) pSignDleqPayload(p Payload, signer BLSParty) (bls.G2Jac, Pf, error) {
+	msg, err := p.Encode()
+	if err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	return b.pSignDleq(msg, signer)
+}
+
+func (b *BLS) hashPayload(p Payload) (bls.G2Affine, error) {
+	msg, err := p.Encode()
+	if err != nil {
+		return bls.G2Affine{}, err
+	}
+	return b.hashMsg(msg)
+}
+
+func (b *BLS) verifyCombinePayload(p Payload, signers []int, sigmas []bls.G2Jac) (bls.G2Jac, error) {
+	roMsg, err := b.hashPayload(p)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.verifyCombine(roMsg, signers, sigmas), nil
+}
+
+func (b *BLS) verifyCombineDleqPayload(p Payload, signers []int, sigmas []bls.G2Jac, pfs []Pf) (bls.G2Jac, error) {
+	roMsg, err := b.hashPayload(p)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.verifyCombineDleq(roMsg, signers, sigmas, pfs), nil
+}
+
+func (b *BLS) gverifyPayload(p Payload, sigma bls.G2Jac) bool {
+	roMsg, err := b.hashPayload(p)
+	if err != nil {
+		return false
+	}
+	return b.gverify(roMsg, sigma)
+}
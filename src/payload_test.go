@@ -0,0 +1,122 @@
+package tss
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustEncode(t *testing.T, p Payload) Message {
+	msg, err := p.Encode()
+	assert.NoError(t, err)
+	return msg
+}
+
+func TestPayloadEncoding(t *testing.T) {
+	p := Payload{Domain: "consensus", Version: 1, Epoch: 2, Round: 3, Body: []byte("block")}
+	dec, err := DecodePayload(mustEncode(t, p))
+	assert.NoError(t, err)
+	assert.Equal(t, p, dec)
+
+	// Payloads whose naive concatenations coincide
+	ambiguous := []Payload{
+		{Domain: "ab", Body: []byte("c")},
+		{Domain: "a", Body: []byte("bc")},
+		{Domain: "abc"},
+		{Body: []byte("abc")},
+		{Domain: "a", Epoch: 1 << 8},
+		{Domain: "a", Round: 1 << 56},
+		{Domain: "a", Version: 1},
+	}
+	for i := range ambiguous {
+		for j := range ambiguous {
+			if i == j {
+				continue
+			}
+			assert.NotEqual(t, mustEncode(t, ambiguous[i]), mustEncode(t, ambiguous[j]), "payloads %d and %d collide", i, j)
+		}
+	}
+
+	// Truncated or extended encodings are rejected
+	enc := mustEncode(t, p)
+	for i := 0; i < len(enc); i++ {
+		_, err := DecodePayload(enc[:i])
+		assert.ErrorIs(t, err, ErrInvalidPayload)
+	}
+	_, err = DecodePayload(append(enc, 0))
+	assert.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestPayloadInjective(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []byte{0, 1, 'a'}
+	randBytes := func() []byte {
+		b := make([]byte, rng.Intn(3))
+		for i := range b {
+			b[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return b
+	}
+
+	seen := make(map[string]Payload)
+	for i := 0; i < 5000; i++ {
+		p := Payload{
+			Domain:  string(randBytes()),
+			Version: uint16(rng.Intn(2)),
+			Epoch:   uint64(rng.Intn(2)),
+			Round:   uint64(rng.Intn(2)),
+			Body:    randBytes(),
+		}
+		enc := string(mustEncode(t, p))
+		if q, ok := seen[enc]; ok {
+			assert.Equal(t, q.Domain, p.Domain)
+			assert.Equal(t, q.Version, p.Version)
+			assert.Equal(t, q.Epoch, p.Epoch)
+			assert.Equal(t, q.Round, p.Round)
+			assert.True(t, bytes.Equal(q.Body, p.Body))
+		}
+		seen[enc] = p
+	}
+}
+
+func TestPayloadSign(t *testing.T) {
+	p := Payload{Domain: "consensus", Version: 1, Epoch: 7, Round: 42, Body: []byte("block")}
+	other := p
+	other.Round++
+
+	n := 1 << 3
+	ths := n / 2
+
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		sigma, pf, err := m.pSignPayload(p, m.pp.signers[i])
+		assert.NoError(t, err)
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	msig, err := m.verifyCombinePayload(p, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverifyPayload(p, msig))
+	assert.False(t, m.gverifyPayload(other, msig))
+
+	b := NewBLS(n, ths, GenBLSCRS(n))
+	sigmas = sigmas[:0]
+	var dleqPfs []Pf
+	for i := 0; i <= ths; i++ {
+		sigma, pf, err := b.pSignDleqPayload(p, b.pp.signers[i])
+		assert.NoError(t, err)
+		sigmas = append(sigmas, sigma)
+		dleqPfs = append(dleqPfs, pf)
+	}
+	bsig, err := b.verifyCombineDleqPayload(p, signers, sigmas, dleqPfs)
+	assert.NoError(t, err)
+	assert.True(t, b.gverifyPayload(p, bsig))
+	assert.False(t, b.gverifyPayload(other, bsig))
+}
@@ -0,0 +1,184 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrMalformedMessage = errors.New("malformed message")
+	ErrNotAllowed       = errors.New("message not in allow-list")
+	ErrStaleSequence    = errors.New("sequence number is not increasing")
+)
+
+// SignPolicy decides whether a party may sign a message. It is consulted
+// before any secret key share is used.
+type SignPolicy interface {
+	Approve(msg Message, signer int) error
+}
+
+// PolicyFunc lets an ordinary function be used as a SignPolicy
+type PolicyFunc func(msg Message, signer int) error
+
+func (f PolicyFunc) Approve(msg Message, signer int) error {
+	return f(msg, signer)
+}
+
+// RefusalError is returned by the signing calls when the policy refuses a message
+type RefusalError struct {
+	Signer int
+	Err    error
+}
+
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("signer %d refused to sign: %v", e.Signer, e.Err)
+}
+
+func (e *RefusalError) Unwrap() error {
+	return e.Err
+}
+
+func checkPolicy(p SignPolicy, msg Message, signer int) error {
+	if p == nil {
+		return nil
+	}
+	if err := p.Approve(msg, signer); err != nil {
+		return &RefusalError{Signer: signer, Err: err}
+	}
+	return nil
+}
+
+// StatefulPolicy is a policy that records what it approves. Check decides
+// without changing any state and Commit records an approved message, so that
+// a combination of policies only updates state once all of them approve.
+type StatefulPolicy interface {
+	SignPolicy
+	Check(msg Message, signer int) error
+	Commit(msg Message, signer int) error
+}
+
+type allOf struct {
+	mu       sync.Mutex
+	policies []SignPolicy
+}
+
+// AllOf approves a message only if every policy approves it. Stateful
+// policies are checked first and only committed once all policies approve.
+func AllOf(policies ...SignPolicy) SignPolicy {
+	return &allOf{policies: policies}
+}
+
+func (a *allOf) Check(msg Message, signer int) error {
+	for _, p := range a.policies {
+		var err error
+		if sp, ok := p.(StatefulPolicy); ok {
+			err = sp.Check(msg, signer)
+		} else {
+			err = p.Approve(msg, signer)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *allOf) Commit(msg Message, signer int) error {
+	for _, p := range a.policies {
+		if sp, ok := p.(StatefulPolicy); ok {
+			if err := sp.Commit(msg, signer); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Requests are serialized so that no other request commits between the
+// checks and the commit
+func (a *allOf) Approve(msg Message, signer int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.Check(msg, signer); err != nil {
+		return err
+	}
+	return a.Commit(msg, signer)
+}
+
+// AllowList approves only messages whose SHA-256 digest has been added
+type AllowList struct {
+	mu      sync.RWMutex
+	digests map[[32]byte]struct{}
+}
+
+func NewAllowList(msgs ...Message) *AllowList {
+	a := &AllowList{digests: make(map[[32]byte]struct{})}
+	for _, msg := range msgs {
+		a.Add(msg)
+	}
+	return a
+}
+
+func (a *AllowList) Add(msg Message) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.digests[sha256.Sum256(msg)] = struct{}{}
+}
+
+func (a *AllowList) Approve(msg Message, signer int) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if _, ok := a.digests[sha256.Sum256(msg)]; !ok {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+// SequencePolicy expects every message to start with a big-endian uint64
+// sequence number and approves it only if it is strictly larger than the
+// last one approved for the same signer.
+type SequencePolicy struct {
+	mu   sync.Mutex
+	last map[int]uint64
+}
+
+func NewSequencePolicy() *SequencePolicy {
+	return &SequencePolicy{last: make(map[int]uint64)}
+}
+
+func (s *SequencePolicy) Approve(msg Message, signer int) error {
+	return s.Commit(msg, signer)
+}
+
+func (s *SequencePolicy) Check(msg Message, signer int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.check(msg, signer)
+	return err
+}
+
+// Checks again under the lock, a concurrent request may have committed since
+func (s *SequencePolicy) Commit(msg Message, signer int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq, err := s.check(msg, signer)
+	if err != nil {
+		return err
+	}
+	s.last[signer] = seq
+	return nil
+}
+
+func (s *SequencePolicy) check(msg Message, signer int) (uint64, error) {
+	if len(msg) < 8 {
+		return 0, ErrMalformedMessage
+	}
+	seq := binary.BigEndian.Uint64(msg[:8])
+	if last, ok := s.last[signer]; ok && seq <= last {
+		return 0, ErrStaleSequence
+	}
+	return seq, nil
+}
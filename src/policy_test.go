@@ -0,0 +1,91 @@
+package tss
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func seqMsg(seq uint64, body string) Message {
+	msg := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint64(msg, seq)
+	return append(msg, body...)
+}
+
+func TestPolicyABLS(t *testing.T) {
+	n := 1 << 3
+	m := NewABLS(n, n/2, GenABLSCRS(n))
+
+	allowed := []byte("hello world")
+	m.SetPolicy(NewAllowList(allowed))
+
+	sigma, _, err := m.pSign(allowed, m.pp.signers[0])
+	assert.NoError(t, err)
+	assert.False(t, sigma.Equal(&bls.G2Jac{}))
+
+	sigma, _, err = m.pSign([]byte("goodbye world"), m.pp.signers[0])
+	var refusal *RefusalError
+	assert.True(t, errors.As(err, &refusal), "expected a refusal")
+	assert.Equal(t, 0, refusal.Signer)
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	assert.True(t, sigma.Equal(&bls.G2Jac{}))
+}
+
+func TestPolicyBLS(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+	m.SetPolicy(NewSequencePolicy())
+
+	_, err := m.psign(seqMsg(1, "first"), m.pp.signers[1])
+	assert.NoError(t, err)
+	_, _, err = m.pSignDleq(seqMsg(2, "second"), m.pp.signers[1])
+	assert.NoError(t, err)
+
+	// Replaying an old sequence number is refused
+	_, err = m.psign(seqMsg(2, "again"), m.pp.signers[1])
+	assert.ErrorIs(t, err, ErrStaleSequence)
+
+	// Sequence numbers are tracked per signer
+	_, err = m.psign(seqMsg(1, "first"), m.pp.signers[2])
+	assert.NoError(t, err)
+
+	// Messages without a sequence number are malformed
+	_, _, err = m.pSignDleq([]byte("short"), m.pp.signers[1])
+	var refusal *RefusalError
+	assert.True(t, errors.As(err, &refusal), "expected a refusal")
+	assert.ErrorIs(t, err, ErrMalformedMessage)
+}
+
+func TestPolicyAllOf(t *testing.T) {
+	msg := seqMsg(7, "payload")
+	schema := PolicyFunc(func(msg Message, signer int) error {
+		if len(msg) != 8+len("payload") {
+			return ErrMalformedMessage
+		}
+		return nil
+	})
+	p := AllOf(schema, NewAllowList(msg), NewSequencePolicy())
+
+	assert.NoError(t, p.Approve(msg, 0))
+	assert.ErrorIs(t, p.Approve(msg, 0), ErrStaleSequence)
+	assert.ErrorIs(t, p.Approve(seqMsg(8, "other"), 0), ErrMalformedMessage)
+	assert.ErrorIs(t, p.Approve(seqMsg(8, "payloa!"), 0), ErrNotAllowed)
+
+	// A refusal by a later policy does not consume the sequence number
+	seq := NewSequencePolicy()
+	allow := NewAllowList()
+	p = AllOf(seq, allow)
+	next := seqMsg(1, "payload")
+	assert.ErrorIs(t, p.Approve(next, 0), ErrNotAllowed)
+	allow.Add(next)
+	assert.NoError(t, p.Approve(next, 0))
+	assert.ErrorIs(t, p.Approve(next, 0), ErrStaleSequence)
+
+	// Nested combinations commit once as well
+	p = AllOf(AllOf(NewSequencePolicy(), schema), NewAllowList(msg))
+	assert.ErrorIs(t, p.Approve(seqMsg(7, "payloa!"), 0), ErrNotAllowed)
+	assert.NoError(t, p.Approve(msg, 0))
+}
@@ -0,0 +1,210 @@
+package tss
+
+import (
+	"errors"
+	"math/big"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+var ErrInvalidPoP = errors.New("invalid proof of possession")
+
+/**************************
+	BOLDYREVA
+***************************/
+
+// A BLS proof of possession is a signature on the public key itself under a
+// DST reserved for proofs of possession.
+func (b *BLS) popPoint(pKey bls.G1Affine) (bls.G2Affine, error) {
+	pkBytes := pKey.Bytes()
+	return bls.HashToG2(pkBytes[:], []byte("BLS_POP_DST"))
+}
+
+func (b *BLS) popProve(signer BLSParty) (bls.G2Jac, error) {
+	pKeyAf := *new(bls.G1Affine).FromJacobian(&signer.pKey)
+	roPop, err := b.popPoint(pKeyAf)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.signPoint(roPop, signer), nil
+}
+
+func (b *BLS) popVerify(pKey bls.G1Affine, pop bls.G2Jac) bool {
+	if validG1(&pKey) != nil {
+		return false
+	}
+	roPop, err := b.popPoint(pKey)
+	if err != nil {
+		return false
+	}
+	return b.pverify(roPop, pop, pKey)
+}
+
+// Replaces the public key of a party, provided it comes with a valid proof of possession
+func (b *BLS) registerKey(index int, pKey bls.G1Affine, pop bls.G2Jac) error {
+	if index < 0 || index >= b.n {
+		return ErrUnknownIndex
+	}
+	if err := validG1(&pKey); err != nil {
+		return err
+	}
+	if !b.popVerify(pKey, pop) {
+		return ErrInvalidPoP
+	}
+	b.pp.pKeys[index] = pKey
+	return nil
+}
+
+// Adds up public keys after checking the proof of possession of every one of them
+func (b *BLS) aggregateKeys(pKeys []bls.G1Affine, pops []bls.G2Jac) (bls.G1Affine, error) {
+	if len(pKeys) != len(pops) {
+		return bls.G1Affine{}, ErrInvalidPoP
+	}
+	var apk bls.G1Jac
+	for i := range pKeys {
+		if !b.popVerify(pKeys[i], pops[i]) {
+			return bls.G1Affine{}, ErrInvalidPoP
+		}
+		apk.AddMixed(&pKeys[i])
+	}
+	return *new(bls.G1Affine).FromJacobian(&apk), nil
+}
+
+// Share of the threshold proof of possession for the group key. Nobody knows
+// the discrete log of pp.pk, so the proof is a threshold signature on it.
+func (b *BLS) groupPopShare(signer BLSParty) (bls.G2Jac, error) {
+	roPop, err := b.popPoint(b.pp.pk)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.signPoint(roPop, signer), nil
+}
+
+func (b *BLS) combineGroupPop(signers []int, shares []bls.G2Jac) (bls.G2Jac, error) {
+	roPop, err := b.popPoint(b.pp.pk)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.verifyCombine(roPop, signers, shares), nil
+}
+
+func (b *BLS) groupPopVerify(pk bls.G1Affine, pop bls.G2Jac) bool {
+	return b.popVerify(pk, pop)
+}
+
+/**************************
+	ADAPTIVE BLS
+***************************/
+
+// Proof of knowledge of the opening (s, r, u) of pKey = g1^s h1^r v1^u
+type PopPf struct {
+	c  fr.Element
+	zs fr.Element
+	zr fr.Element
+	zu fr.Element
+}
+
+func (b *ABLS) popChallenge(index int, pKey bls.G1Jac, a bls.G1Jac) fr.Element {
+	pts := bls.BatchJacobianToAffineG1([]bls.G1Jac{pKey, a})
+	t := newTranscript("ABLS-POP")
+	t.appendBytes("crs", b.crs.fp[:])
+	t.appendUint64("index", uint64(index))
+	t.appendG1("pkey", pts[0])
+	t.appendG1("a", pts[1])
+	return t.challenge()
+}
+
+func (b *ABLS) popProve(signer ABLSParty) PopPf {
+	var ks, kr, ku fr.Element
+	ks.SetRandom()
+	kr.SetRandom()
+	ku.SetRandom()
+
+	a := b.commitG1(ks, kr, ku)
+
+	c := b.popChallenge(signer.index, signer.pKey, a)
+
+	var zs, zr, zu fr.Element
+	zs.Add(zs.Mul(&c, &signer.sKey), &ks)
+	zr.Add(zr.Mul(&c, &signer.rKey), &kr)
+	zu.Add(zu.Mul(&c, &signer.uKey), &ku)
+
+	return PopPf{c, zs, zr, zu}
+}
+
+func (b *ABLS) popVerify(index int, pKeyAf bls.G1Affine, pf PopPf) bool {
+	if validG1(&pKeyAf) != nil {
+		return false
+	}
+	pKey := *new(bls.G1Jac).FromAffine(&pKeyAf)
+
+	var pkC bls.G1Jac
+	a := b.commitG1(pf.zs, pf.zr, pf.zu)
+	pkC.ScalarMultiplication(&pKey, pf.c.BigInt(&big.Int{}))
+	a.SubAssign(&pkC)
+
+	cLocal := b.popChallenge(index, pKey, a)
+	return pf.c.Equal(&cLocal)
+}
+
+func (b *ABLS) registerKey(index int, pKey bls.G1Affine, pf PopPf) error {
+	if index < 0 || index >= b.n {
+		return ErrUnknownIndex
+	}
+	if err := validG1(&pKey); err != nil {
+		return err
+	}
+	if !b.popVerify(index, pKey, pf) {
+		return ErrInvalidPoP
+	}
+	b.pp.pKeys[index] = pKey
+	return nil
+}
+
+func (b *ABLS) popPoints(pk bls.G1Affine) (bls.G2Affine, bls.G2Affine, error) {
+	pkBytes := pk.Bytes()
+	ro0Pop, err := bls.HashToG2(pkBytes[:], []byte("ABLS_POP_DST0"))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	ro1Pop, err := bls.HashToG2(pkBytes[:], []byte("ABLS_POP_DST1"))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	return ro0Pop, ro1Pop, nil
+}
+
+// Share of the threshold proof of possession for the group key
+func (b *ABLS) groupPopShare(signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	ro0Pop, ro1Pop, err := b.popPoints(b.pp.pk)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	sigma, pf := b.signPoints(nil, ro0Pop, ro1Pop, signer)
+	return sigma, pf, nil
+}
+
+func (b *ABLS) combineGroupPop(signers []int, shares []bls.G2Jac, pfs []SigmaPf) (bls.G2Jac, error) {
+	ro0Pop, ro1Pop, err := b.popPoints(b.pp.pk)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.verifyCombine(ro0Pop, ro1Pop, signers, shares, pfs), nil
+}
+
+func (b *ABLS) groupPopVerify(pk bls.G1Affine, pop bls.G2Jac) bool {
+	if validG1(&pk) != nil {
+		return false
+	}
+	popAf, err := validG2Jac(&pop)
+	if err != nil {
+		return false
+	}
+	ro0Pop, _, err := b.popPoints(pk)
+	if err != nil {
+		return false
+	}
+	res, _ := bls.PairingCheck([]bls.G1Affine{pk, b.crs.g1InvAf}, []bls.G2Affine{ro0Pop, popAf})
+	return res
+}
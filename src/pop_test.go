@@ -0,0 +1,102 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPopBLS(t *testing.T) {
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	mustPop := func(signer BLSParty) bls.G2Jac {
+		pop, err := m.popProve(signer)
+		assert.NoError(t, err)
+		return pop
+	}
+
+	pop := mustPop(m.pp.signers[1])
+	assert.True(t, m.popVerify(m.pp.pKeys[1], pop))
+	assert.NoError(t, m.registerKey(1, m.pp.pKeys[1], pop))
+
+	// Somebody else's proof does not work for a key
+	assert.ErrorIs(t, m.registerKey(1, m.pp.pKeys[1], mustPop(m.pp.signers[2])), ErrInvalidPoP)
+	assert.ErrorIs(t, m.registerKey(n, m.pp.pKeys[1], pop), ErrUnknownIndex)
+
+	// A rogue key pk' = g^x - pk_0 can not come with a valid proof
+	var rogue bls.G1Affine
+	rogue.Sub(&m.pp.pKeys[2], &m.pp.pKeys[0])
+	assert.ErrorIs(t, m.registerKey(3, rogue, mustPop(m.pp.signers[2])), ErrInvalidPoP)
+
+	// Keys outside the subgroup or the identity are refused before the proof
+	assert.ErrorIs(t, m.registerKey(3, bls.G1Affine{}, pop), ErrInvalidPoint)
+	assert.ErrorIs(t, m.registerKey(3, nonSubgroupG1(), pop), ErrInvalidPoint)
+
+	pops := []bls.G2Jac{mustPop(m.pp.signers[0]), mustPop(m.pp.signers[1])}
+	apk, err := m.aggregateKeys(m.pp.pKeys[:2], pops)
+	assert.NoError(t, err)
+	var expected bls.G1Affine
+	expected.Add(&m.pp.pKeys[0], &m.pp.pKeys[1])
+	assert.True(t, apk.Equal(&expected))
+	_, err = m.aggregateKeys([]bls.G1Affine{m.pp.pKeys[0], rogue}, pops)
+	assert.ErrorIs(t, err, ErrInvalidPoP)
+
+	var signers []int
+	var shares []bls.G2Jac
+	for i := 0; i <= ths; i++ {
+		signers = append(signers, i)
+		share, err := m.groupPopShare(m.pp.signers[i])
+		assert.NoError(t, err)
+		shares = append(shares, share)
+	}
+	groupPop, err := m.combineGroupPop(signers, shares)
+	assert.NoError(t, err)
+	assert.True(t, m.groupPopVerify(m.pp.pk, groupPop))
+	assert.False(t, m.groupPopVerify(m.pp.pKeys[0], groupPop))
+
+	// Identity and non-subgroup points are refused
+	assert.False(t, m.groupPopVerify(bls.G1Affine{}, bls.G2Jac{}))
+	assert.False(t, m.groupPopVerify(nonSubgroupG1(), groupPop))
+	bad := nonSubgroupG2()
+	assert.False(t, m.groupPopVerify(m.pp.pk, *new(bls.G2Jac).FromAffine(&bad)))
+}
+
+func TestPopABLS(t *testing.T) {
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+
+	pf := m.popProve(m.pp.signers[1])
+	assert.True(t, m.popVerify(1, m.pp.pKeys[1], pf))
+	assert.NoError(t, m.registerKey(1, m.pp.pKeys[1], pf))
+
+	// The proof is bound to the key and to the index it is registered at
+	assert.ErrorIs(t, m.registerKey(2, m.pp.pKeys[1], pf), ErrInvalidPoP)
+	assert.ErrorIs(t, m.registerKey(1, m.pp.pKeys[2], pf), ErrInvalidPoP)
+	assert.ErrorIs(t, m.registerKey(-1, m.pp.pKeys[1], pf), ErrUnknownIndex)
+	assert.ErrorIs(t, m.registerKey(1, bls.G1Affine{}, pf), ErrInvalidPoint)
+	assert.ErrorIs(t, m.registerKey(1, nonSubgroupG1(), pf), ErrInvalidPoint)
+
+	var signers []int
+	var shares []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		share, pf, err := m.groupPopShare(m.pp.signers[i])
+		assert.NoError(t, err)
+		signers = append(signers, i)
+		shares = append(shares, share)
+		pfs = append(pfs, pf)
+	}
+	groupPop, err := m.combineGroupPop(signers, shares, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.groupPopVerify(m.pp.pk, groupPop))
+	assert.False(t, m.groupPopVerify(m.pp.pKeys[0], groupPop))
+
+	// Identity and non-subgroup points are refused
+	assert.False(t, m.groupPopVerify(bls.G1Affine{}, bls.G2Jac{}))
+	assert.False(t, m.groupPopVerify(nonSubgroupG1(), groupPop))
+	bad := nonSubgroupG2()
+	assert.False(t, m.groupPopVerify(m.pp.pk, *new(bls.G2Jac).FromAffine(&bad)))
+}
@@ -0,0 +1,84 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+var ErrInvalidProof = errors.New("invalid proof encoding")
+
+/*
+* Wire formats of the ABLS partial proofs. The challenge form (c, zs, zr, zu)
+* is the smallest, but each proof has to be checked on its own since the
+* verifier recomputes the commitments. The commitment form (x, y, zs, zr, zu)
+* is 112 bytes longer and many proofs can be checked in one MSM per group, see
+* pVerifyBatch. Scalars must be canonical, and points are rejected on decoding
+* if they are the identity or outside the subgroup, as validG1 and validG2 do.
+ */
+
+const (
+	SigmaPfSize    = 4 * fr.Bytes
+	SigmaCmtPfSize = bls.SizeOfG1AffineCompressed + bls.SizeOfG2AffineCompressed + 3*fr.Bytes
+)
+
+func appendScalars(buf []byte, els ...*fr.Element) []byte {
+	for _, e := range els {
+		eBytes := e.Bytes()
+		buf = append(buf, eBytes[:]...)
+	}
+	return buf
+}
+
+func setScalars(data []byte, els ...*fr.Element) error {
+	for i, e := range els {
+		if err := e.SetBytesCanonical(data[i*fr.Bytes : (i+1)*fr.Bytes]); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+		}
+	}
+	return nil
+}
+
+// Encoded as c || zs || zr || zu
+func (pf *SigmaPf) Bytes() []byte {
+	return appendScalars(make([]byte, 0, SigmaPfSize), &pf.c, &pf.zs, &pf.zr, &pf.zu)
+}
+
+func (pf *SigmaPf) SetBytes(data []byte) error {
+	if len(data) != SigmaPfSize {
+		return ErrInvalidProof
+	}
+	return setScalars(data, &pf.c, &pf.zs, &pf.zr, &pf.zu)
+}
+
+// Encoded as x (48 bytes) || y (96 bytes) || zs || zr || zu
+func (pf *SigmaCmtPf) Bytes() []byte {
+	xBytes, yBytes := pf.x.Bytes(), pf.y.Bytes()
+	buf := make([]byte, 0, SigmaCmtPfSize)
+	buf = append(buf, xBytes[:]...)
+	buf = append(buf, yBytes[:]...)
+	return appendScalars(buf, &pf.zs, &pf.zr, &pf.zu)
+}
+
+func (pf *SigmaCmtPf) SetBytes(data []byte) error {
+	if len(data) != SigmaCmtPfSize {
+		return ErrInvalidProof
+	}
+	n, err := pf.x.SetBytes(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	m, err := pf.y.SetBytes(data[n:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if err := validG1(&pf.x); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if err := validG2(&pf.y); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	return setScalars(data[n+m:], &pf.zs, &pf.zr, &pf.zu)
+}
@@ -0,0 +1,110 @@
+package tss
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofEncoding(t *testing.T) {
+	n := 1 << 3
+	m := NewABLS(n, n/2, GenABLSCRS(n))
+	msg := []byte("hello world")
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	sigma, pf, _ := m.pSign(msg, m.pp.signers[0])
+	data := pf.Bytes()
+	assert.Len(t, data, SigmaPfSize)
+	var pf2 SigmaPf
+	assert.NoError(t, pf2.SetBytes(data))
+	assert.True(t, m.pVerify(ro0Msg, ro1Msg, sigma, 0, pf2))
+
+	sigma, cmtPf, _ := m.pSignCmt(msg, m.pp.signers[0])
+	data = cmtPf.Bytes()
+	assert.Len(t, data, SigmaCmtPfSize)
+	var cmtPf2 SigmaCmtPf
+	assert.NoError(t, cmtPf2.SetBytes(data))
+	assert.Equal(t, cmtPf, cmtPf2)
+	assert.Empty(t, m.pVerifyBatch(ro0Msg, ro1Msg, []int{0}, []bls.G2Jac{sigma}, []SigmaCmtPf{cmtPf2}))
+
+	// Truncated and padded encodings
+	assert.ErrorIs(t, cmtPf2.SetBytes(data[:SigmaCmtPfSize-1]), ErrInvalidProof)
+	assert.ErrorIs(t, cmtPf2.SetBytes(append(data, 0)), ErrInvalidProof)
+	assert.ErrorIs(t, pf2.SetBytes(data), ErrInvalidProof)
+
+	// Non-canonical scalar
+	bad := append([]byte{}, data...)
+	copy(bad[SigmaCmtPfSize-32:], bytes.Repeat([]byte{0xff}, 32))
+	assert.ErrorIs(t, cmtPf2.SetBytes(bad), ErrInvalidProof)
+
+	// Commitment outside the subgroup
+	bad = append([]byte{}, data...)
+	y := nonSubgroupG2()
+	yBytes := y.Bytes()
+	copy(bad[bls.SizeOfG1AffineCompressed:], yBytes[:])
+	assert.ErrorIs(t, cmtPf2.SetBytes(bad), ErrInvalidProof)
+
+	// Commitments at infinity
+	var inf1 bls.G1Affine
+	var inf2 bls.G2Affine
+	inf1Bytes, inf2Bytes := inf1.Bytes(), inf2.Bytes()
+	bad = append([]byte{}, data...)
+	copy(bad, inf1Bytes[:])
+	assert.ErrorIs(t, cmtPf2.SetBytes(bad), ErrInvalidProof)
+	bad = append([]byte{}, data...)
+	copy(bad[bls.SizeOfG1AffineCompressed:], inf2Bytes[:])
+	assert.ErrorIs(t, cmtPf2.SetBytes(bad), ErrInvalidProof)
+}
+
+func BenchmarkProofFormats(b *testing.B) {
+	n := 1 << 7
+	m := NewABLS(n, n-1, GenABLSCRS(n))
+	msg := []byte("hello world")
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	for _, k := range []int{16, 64, 128} {
+		signers := GetRange(0, k)
+		sigmas := make([]bls.G2Jac, k)
+		pfData := make([][]byte, k)
+		cmtData := make([][]byte, k)
+		for i := range signers {
+			var pf SigmaPf
+			var cmtPf SigmaCmtPf
+			sigmas[i], pf, _ = m.pSign(msg, m.pp.signers[i])
+			_, cmtPf, _ = m.pSignCmt(msg, m.pp.signers[i])
+			pfData[i], cmtData[i] = pf.Bytes(), cmtPf.Bytes()
+		}
+
+		// Decoding is part of the cost, the commitment form checks two points
+		b.Run(fmt.Sprintf("%d-SigmaPf", k), func(b *testing.B) {
+			b.ReportMetric(float64(k*SigmaPfSize), "proof-bytes")
+			for i := 0; i < b.N; i++ {
+				pfs := make([]SigmaPf, k)
+				for j := range pfs {
+					pfs[j].SetBytes(pfData[j])
+				}
+				for j := range pfs {
+					if !m.pVerify(ro0Msg, ro1Msg, sigmas[j], signers[j], pfs[j]) {
+						b.Fatal("proof rejected")
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("%d-SigmaCmtPf", k), func(b *testing.B) {
+			b.ReportMetric(float64(k*SigmaCmtPfSize), "proof-bytes")
+			for i := 0; i < b.N; i++ {
+				pfs := make([]SigmaCmtPf, k)
+				for j := range pfs {
+					pfs[j].SetBytes(cmtData[j])
+				}
+				if bad := m.pVerifyBatch(ro0Msg, ro1Msg, signers, sigmas, pfs); len(bad) != 0 {
+					b.Fatal("proof rejected")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package tss
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+const interchangeVersion = "1"
+
+var ErrDBClosed = errors.New("protection database is closed")
+
+// EquivocationError is returned when a signer is asked to sign a second,
+// different message for an (epoch, slot) it already signed.
+type EquivocationError struct {
+	PubKey []byte
+	Epoch  uint64
+	Slot   uint64
+}
+
+func (e *EquivocationError) Error() string {
+	return fmt.Sprintf("key %x already signed a different message at epoch %d slot %d", e.PubKey, e.Epoch, e.Slot)
+}
+
+type slotKey struct {
+	pubKey string
+	epoch  uint64
+	slot   uint64
+}
+
+// Every record is appended to the log as a single JSON line
+type slotRecord struct {
+	PubKey      string `json:"pubkey"`
+	Epoch       string `json:"epoch"`
+	Slot        string `json:"slot"`
+	SigningRoot string `json:"signing_root"`
+}
+
+// The operations the database needs from its log, satisfied by *os.File
+type logFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// ProtectionDB is an append-only, file-backed slashing protection store. A
+// record is synced to disk before the partial signature it protects is
+// released, so a crash can never forget a slot that was signed.
+type ProtectionDB struct {
+	mu      sync.Mutex
+	file    logFile
+	records map[slotKey][32]byte
+}
+
+// OpenProtectionDB opens (or creates) the database stored at path and
+// replays its log. A torn last record left behind by a crash is discarded.
+func OpenProtectionDB(path string) (*ProtectionDB, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	db := &ProtectionDB{
+		file:    file,
+		records: make(map[slotKey][32]byte),
+	}
+	if err := db.replay(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *ProtectionDB) replay() error {
+	data, err := io.ReadAll(db.file)
+	if err != nil {
+		return err
+	}
+
+	valid := 0
+	for valid < len(data) {
+		end := bytes.IndexByte(data[valid:], '\n')
+		if end < 0 {
+			// Unterminated record: the write never completed
+			break
+		}
+		var rec slotRecord
+		if err := json.Unmarshal(data[valid:valid+end], &rec); err != nil {
+			return fmt.Errorf("corrupt protection record at offset %d: %w", valid, err)
+		}
+		key, root, err := rec.decode()
+		if err != nil {
+			return err
+		}
+		db.records[key] = root
+		valid += end + 1
+	}
+
+	if valid != len(data) {
+		if err := db.file.Truncate(int64(valid)); err != nil {
+			return err
+		}
+	}
+	_, err = db.file.Seek(int64(valid), io.SeekStart)
+	return err
+}
+
+func (db *ProtectionDB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return nil
+	}
+	err := db.file.Close()
+	db.file = nil
+	return err
+}
+
+// check reports whether pubKey may sign root at (epoch, slot) without
+// recording anything, so a refused request never reaches the signer.
+func (db *ProtectionDB) check(pubKey []byte, epoch, slot uint64, root [32]byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	_, err := db.lookup(pubKey, epoch, slot, root)
+	return err
+}
+
+// checkAndRecord persists that pubKey signs root at (epoch, slot). Signing
+// the same root again is allowed, a different root is refused. It checks
+// again under the lock, a concurrent request may have recorded the slot.
+func (db *ProtectionDB) checkAndRecord(pubKey []byte, epoch, slot uint64, root [32]byte) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	key, err := db.lookup(pubKey, epoch, slot, root)
+	if err != nil || key == nil {
+		return err
+	}
+
+	if err := db.append([]slotKey{*key}, [][32]byte{root}); err != nil {
+		return err
+	}
+	db.records[*key] = root
+	return nil
+}
+
+// Returns the key still to be recorded, or nil if root is already recorded
+func (db *ProtectionDB) lookup(pubKey []byte, epoch, slot uint64, root [32]byte) (*slotKey, error) {
+	if db.file == nil {
+		return nil, ErrDBClosed
+	}
+
+	key := slotKey{hex.EncodeToString(pubKey), epoch, slot}
+	if prev, ok := db.records[key]; ok {
+		if prev != root {
+			return nil, &EquivocationError{PubKey: pubKey, Epoch: epoch, Slot: slot}
+		}
+		return nil, nil
+	}
+	return &key, nil
+}
+
+func (db *ProtectionDB) append(keys []slotKey, roots [][32]byte) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range keys {
+		if err := enc.Encode(newSlotRecord(keys[i], roots[i])); err != nil {
+			return err
+		}
+	}
+
+	off, err := db.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := db.file.Write(buf.Bytes()); err != nil {
+		return db.rollback(off, err)
+	}
+	if err := db.file.Sync(); err != nil {
+		// After a failed fsync it is unknown what reached the disk and later
+		// syncs may report success for lost pages, so the database stops here
+		db.rollback(off, err)
+		db.fail()
+		return fmt.Errorf("%w: sync failed: %v", ErrDBClosed, err)
+	}
+	return nil
+}
+
+// Cuts a partially written record off the log. If that fails too, the next
+// record would follow a torn line, so the database is closed.
+func (db *ProtectionDB) rollback(off int64, err error) error {
+	if terr := db.file.Truncate(off); terr == nil {
+		if _, terr = db.file.Seek(off, io.SeekStart); terr == nil {
+			return err
+		}
+	}
+	db.fail()
+	return fmt.Errorf("%w: %v", ErrDBClosed, err)
+}
+
+func (db *ProtectionDB) fail() {
+	db.file.Close()
+	db.file = nil
+}
+
+type interchange struct {
+	Metadata struct {
+		Version string `json:"interchange_format_version"`
+	} `json:"metadata"`
+	Data []interchangeKey `json:"data"`
+}
+
+type interchangeKey struct {
+	PubKey         string            `json:"pubkey"`
+	SignedMessages []interchangeSlot `json:"signed_messages"`
+}
+
+type interchangeSlot struct {
+	Epoch       string `json:"epoch"`
+	Slot        string `json:"slot"`
+	SigningRoot string `json:"signing_root"`
+}
+
+// Export writes every record in an interchange format modelled on EIP-3076
+func (db *ProtectionDB) Export(w io.Writer) error {
+	db.mu.Lock()
+	keys := make([]slotKey, 0, len(db.records))
+	for key := range db.records {
+		keys = append(keys, key)
+	}
+	records := make(map[slotKey][32]byte, len(db.records))
+	for key, root := range db.records {
+		records[key] = root
+	}
+	db.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pubKey != keys[j].pubKey {
+			return keys[i].pubKey < keys[j].pubKey
+		}
+		if keys[i].epoch != keys[j].epoch {
+			return keys[i].epoch < keys[j].epoch
+		}
+		return keys[i].slot < keys[j].slot
+	})
+
+	var out interchange
+	out.Metadata.Version = interchangeVersion
+	out.Data = []interchangeKey{}
+	for _, key := range keys {
+		if len(out.Data) == 0 || out.Data[len(out.Data)-1].PubKey != "0x"+key.pubKey {
+			out.Data = append(out.Data, interchangeKey{PubKey: "0x" + key.pubKey})
+		}
+		rec := newSlotRecord(key, records[key])
+		last := &out.Data[len(out.Data)-1]
+		last.SignedMessages = append(last.SignedMessages, interchangeSlot{rec.Epoch, rec.Slot, rec.SigningRoot})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// Import merges the records of an interchange file. Nothing is imported if
+// any record conflicts with the database or with another imported record.
+func (db *ProtectionDB) Import(r io.Reader) error {
+	var in interchange
+	if err := json.NewDecoder(bufio.NewReader(r)).Decode(&in); err != nil {
+		return err
+	}
+	if in.Metadata.Version != interchangeVersion {
+		return fmt.Errorf("unsupported interchange format version %q", in.Metadata.Version)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return ErrDBClosed
+	}
+
+	var keys []slotKey
+	var roots [][32]byte
+	pending := make(map[slotKey][32]byte)
+	for _, k := range in.Data {
+		for _, s := range k.SignedMessages {
+			key, root, err := slotRecord{k.PubKey, s.Epoch, s.Slot, s.SigningRoot}.decode()
+			if err != nil {
+				return err
+			}
+			prev, ok := db.records[key]
+			if !ok {
+				prev, ok = pending[key]
+			}
+			if ok {
+				if prev != root {
+					pk, _ := hex.DecodeString(key.pubKey)
+					return &EquivocationError{PubKey: pk, Epoch: key.epoch, Slot: key.slot}
+				}
+				continue
+			}
+			pending[key] = root
+			keys = append(keys, key)
+			roots = append(roots, root)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := db.append(keys, roots); err != nil {
+		return err
+	}
+	for key, root := range pending {
+		db.records[key] = root
+	}
+	return nil
+}
+
+func newSlotRecord(key slotKey, root [32]byte) slotRecord {
+	return slotRecord{
+		PubKey:      "0x" + key.pubKey,
+		Epoch:       strconv.FormatUint(key.epoch, 10),
+		Slot:        strconv.FormatUint(key.slot, 10),
+		SigningRoot: "0x" + hex.EncodeToString(root[:]),
+	}
+}
+
+func (rec slotRecord) decode() (slotKey, [32]byte, error) {
+	var key slotKey
+	var root [32]byte
+
+	pk, err := hex.DecodeString(strings.TrimPrefix(rec.PubKey, "0x"))
+	if err != nil {
+		return key, root, fmt.Errorf("invalid pubkey %q: %w", rec.PubKey, err)
+	}
+	if key.epoch, err = strconv.ParseUint(rec.Epoch, 10, 64); err != nil {
+		return key, root, fmt.Errorf("invalid epoch %q: %w", rec.Epoch, err)
+	}
+	if key.slot, err = strconv.ParseUint(rec.Slot, 10, 64); err != nil {
+		return key, root, fmt.Errorf("invalid slot %q: %w", rec.Slot, err)
+	}
+	r, err := hex.DecodeString(strings.TrimPrefix(rec.SigningRoot, "0x"))
+	if err != nil || len(r) != len(root) {
+		return key, root, fmt.Errorf("invalid signing root %q", rec.SigningRoot)
+	}
+	key.pubKey = hex.EncodeToString(pk)
+	copy(root[:], r)
+	return key, root, nil
+}
+
+func signingRoot(msg Message) [32]byte {
+	return sha256.Sum256(msg)
+}
+
+func pubKeyBytes(pk bls.G1Jac) []byte {
+	pkAff := *new(bls.G1Affine).FromJacobian(&pk)
+	pkBytes := pkAff.Bytes()
+	return pkBytes[:]
+}
+
+// Partial signature for a slot. The protection database is consulted before
+// signing, so a refused slot neither uses the share nor commits the policy,
+// and the signature is released only after the database durably records it.
+func (b *ABLS) pSignSlot(db *ProtectionDB, epoch, slot uint64, msg Message, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	pk, root := pubKeyBytes(signer.pKey), signingRoot(msg)
+	if err := db.check(pk, epoch, slot, root); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	sigma, pf, err := b.pSign(msg, signer)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	if err := db.checkAndRecord(pk, epoch, slot, root); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	return sigma, pf, nil
+}
+
+func (b *BLS) psignSlot(db *ProtectionDB, epoch, slot uint64, msg Message, signer BLSParty) (bls.G2Jac, error) {
+	pk, root := pubKeyBytes(signer.pKey), signingRoot(msg)
+	if err := db.check(pk, epoch, slot, root); err != nil {
+		return bls.G2Jac{}, err
+	}
+	sigma, err := b.psign(msg, signer)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	if err := db.checkAndRecord(pk, epoch, slot, root); err != nil {
+		return bls.G2Jac{}, err
+	}
+	return sigma, nil
+}
+
+func (b *BLS) pSignDleqSlot(db *ProtectionDB, epoch, slot uint64, msg Message, signer BLSParty) (bls.G2Jac, Pf, error) {
+	pk, root := pubKeyBytes(signer.pKey), signingRoot(msg)
+	if err := db.check(pk, epoch, slot, root); err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	sigma, pf, err := b.pSignDleq(msg, signer)
+	if err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	if err := db.checkAndRecord(pk, epoch, slot, root); err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	return sigma, pf, nil
+}
@@ -0,0 +1,215 @@
+package tss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectionABLS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "protection.db")
+	db, err := OpenProtectionDB(path)
+	assert.NoError(t, err)
+
+	n := 1 << 3
+	m := NewABLS(n, n/2, GenABLSCRS(n))
+	signer := m.pp.signers[0]
+
+	_, _, err = m.pSignSlot(db, 1, 10, []byte("block A"), signer)
+	assert.NoError(t, err)
+
+	// Re-signing the same message is harmless
+	_, _, err = m.pSignSlot(db, 1, 10, []byte("block A"), signer)
+	assert.NoError(t, err)
+
+	_, _, err = m.pSignSlot(db, 1, 10, []byte("block B"), signer)
+	var equivocation *EquivocationError
+	assert.True(t, errors.As(err, &equivocation), "expected an equivocation")
+	assert.Equal(t, uint64(10), equivocation.Slot)
+
+	// Other signers and other slots are unaffected
+	_, _, err = m.pSignSlot(db, 1, 10, []byte("block B"), m.pp.signers[1])
+	assert.NoError(t, err)
+	_, _, err = m.pSignSlot(db, 1, 11, []byte("block B"), signer)
+	assert.NoError(t, err)
+
+	// The records survive a restart
+	assert.NoError(t, db.Close())
+	db, err = OpenProtectionDB(path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, _, err = m.pSignSlot(db, 1, 10, []byte("block B"), signer)
+	assert.True(t, errors.As(err, &equivocation), "expected an equivocation after restart")
+}
+
+func TestProtectionBLS(t *testing.T) {
+	db, err := OpenProtectionDB(filepath.Join(t.TempDir(), "protection.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+	signer := m.pp.signers[2]
+
+	_, err = m.psignSlot(db, 0, 1, []byte("block A"), signer)
+	assert.NoError(t, err)
+	_, _, err = m.pSignDleqSlot(db, 0, 1, []byte("block B"), signer)
+	var equivocation *EquivocationError
+	assert.True(t, errors.As(err, &equivocation), "expected an equivocation")
+
+	// A refused message is never recorded
+	m.SetPolicy(NewAllowList([]byte("block D")))
+	_, err = m.psignSlot(db, 0, 2, []byte("block C"), signer)
+	var refusal *RefusalError
+	assert.True(t, errors.As(err, &refusal), "expected a refusal")
+	_, err = m.psignSlot(db, 0, 2, []byte("block D"), signer)
+	assert.NoError(t, err)
+}
+
+// The database is consulted before signing, a refused slot leaves the
+// policy untouched
+func TestProtectionBeforePolicy(t *testing.T) {
+	db, err := OpenProtectionDB(filepath.Join(t.TempDir(), "protection.db"))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+	m.SetPolicy(NewSequencePolicy())
+	signer := m.pp.signers[2]
+	seq := func(s uint64, body string) Message {
+		return append(binary.BigEndian.AppendUint64(nil, s), body...)
+	}
+
+	_, err = m.psignSlot(db, 0, 1, seq(1, "block A"), signer)
+	assert.NoError(t, err)
+	_, err = m.psignSlot(db, 0, 1, seq(5, "block B"), signer)
+	var equivocation *EquivocationError
+	assert.True(t, errors.As(err, &equivocation), "expected an equivocation")
+
+	// Sequence 5 was never committed
+	_, err = m.psignSlot(db, 0, 2, seq(3, "block C"), signer)
+	assert.NoError(t, err)
+
+	assert.NoError(t, db.Close())
+	_, _, err = m.pSignDleqSlot(db, 0, 3, seq(4, "block D"), signer)
+	assert.ErrorIs(t, err, ErrDBClosed)
+	reopened, err := OpenProtectionDB(filepath.Join(t.TempDir(), "protection.db"))
+	assert.NoError(t, err)
+	defer reopened.Close()
+	_, _, err = m.pSignDleqSlot(reopened, 0, 3, seq(4, "block D"), signer)
+	assert.NoError(t, err)
+}
+
+func TestProtectionTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "protection.db")
+	db, err := OpenProtectionDB(path)
+	assert.NoError(t, err)
+	assert.NoError(t, db.checkAndRecord([]byte{1}, 0, 1, signingRoot([]byte("a"))))
+	assert.NoError(t, db.Close())
+
+	// Simulate a crash in the middle of appending a record
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"pubkey":"0x01","epoch":"0","sl`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	db, err = OpenProtectionDB(path)
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.Error(t, db.checkAndRecord([]byte{1}, 0, 1, signingRoot([]byte("b"))))
+	assert.NoError(t, db.checkAndRecord([]byte{1}, 0, 2, signingRoot([]byte("b"))))
+}
+
+// Writes half of the buffer before failing, or fails the sync
+type faultyFile struct {
+	*os.File
+	failWrite, failSync bool
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.failWrite {
+		n, _ := f.File.Write(p[:len(p)/2])
+		return n, errors.New("short write")
+	}
+	return f.File.Write(p)
+}
+
+func (f *faultyFile) Sync() error {
+	if f.failSync {
+		return errors.New("sync failed")
+	}
+	return f.File.Sync()
+}
+
+func TestProtectionFailedAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "protection.db")
+	db, err := OpenProtectionDB(path)
+	assert.NoError(t, err)
+	assert.NoError(t, db.checkAndRecord([]byte{1}, 0, 1, signingRoot([]byte("a"))))
+
+	// A short write is cut off and the database stays usable
+	faulty := &faultyFile{File: db.file.(*os.File), failWrite: true}
+	db.file = faulty
+	assert.Error(t, db.checkAndRecord([]byte{1}, 0, 2, signingRoot([]byte("b"))))
+	faulty.failWrite = false
+	assert.NoError(t, db.checkAndRecord([]byte{1}, 0, 3, signingRoot([]byte("c"))))
+
+	// A failed sync closes the database
+	faulty.failSync = true
+	err = db.checkAndRecord([]byte{1}, 0, 4, signingRoot([]byte("d")))
+	assert.ErrorIs(t, err, ErrDBClosed)
+	assert.ErrorIs(t, db.checkAndRecord([]byte{1}, 0, 4, signingRoot([]byte("e"))), ErrDBClosed)
+
+	// No torn line is left behind and the slots can be signed after reopening
+	db, err = OpenProtectionDB(path)
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.Len(t, db.records, 2)
+	assert.NoError(t, db.checkAndRecord([]byte{1}, 0, 2, signingRoot([]byte("b"))))
+	assert.NoError(t, db.checkAndRecord([]byte{1}, 0, 4, signingRoot([]byte("e"))))
+}
+
+func TestProtectionInterchange(t *testing.T) {
+	src, err := OpenProtectionDB(filepath.Join(t.TempDir(), "src.db"))
+	assert.NoError(t, err)
+	defer src.Close()
+	assert.NoError(t, src.checkAndRecord([]byte{1}, 0, 1, signingRoot([]byte("a"))))
+	assert.NoError(t, src.checkAndRecord([]byte{1}, 0, 2, signingRoot([]byte("b"))))
+	assert.NoError(t, src.checkAndRecord([]byte{2}, 3, 1, signingRoot([]byte("c"))))
+
+	var exported bytes.Buffer
+	assert.NoError(t, src.Export(&exported))
+
+	dst, err := OpenProtectionDB(filepath.Join(t.TempDir(), "dst.db"))
+	assert.NoError(t, err)
+	defer dst.Close()
+	assert.NoError(t, dst.Import(bytes.NewReader(exported.Bytes())))
+
+	var reexported bytes.Buffer
+	assert.NoError(t, dst.Export(&reexported))
+	assert.Equal(t, exported.String(), reexported.String())
+
+	var equivocation *EquivocationError
+	err = dst.checkAndRecord([]byte{2}, 3, 1, signingRoot([]byte("d")))
+	assert.True(t, errors.As(err, &equivocation), "imported records must be enforced")
+
+	// Conflicting imports are rejected as a whole
+	other, err := OpenProtectionDB(filepath.Join(t.TempDir(), "other.db"))
+	assert.NoError(t, err)
+	defer other.Close()
+	assert.NoError(t, other.checkAndRecord([]byte{3}, 0, 0, signingRoot([]byte("e"))))
+	assert.NoError(t, other.checkAndRecord([]byte{1}, 0, 1, signingRoot([]byte("f"))))
+	exported.Reset()
+	assert.NoError(t, other.Export(&exported))
+	err = dst.Import(&exported)
+	assert.True(t, errors.As(err, &equivocation), "expected a conflicting import")
+	assert.NoError(t, dst.checkAndRecord([]byte{3}, 0, 0, signingRoot([]byte("g"))))
+}
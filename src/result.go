@@ -0,0 +1,159 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+var ErrTooFewPartials = errors.New("not enough valid partial signatures")
+
+// Why a partial signature was left out of a combination
+type RejectReason int
+
+const (
+	RejectBadProof RejectReason = iota + 1
+	RejectUnknownIndex
+	RejectDuplicate
+	RejectMalformedPoint
+)
+
+func (r RejectReason) String() string {
+	switch r {
+	case RejectBadProof:
+		return "bad proof"
+	case RejectUnknownIndex:
+		return "unknown index"
+	case RejectDuplicate:
+		return "duplicate"
+	case RejectMalformedPoint:
+		return "malformed point"
+	}
+	return fmt.Sprintf("RejectReason(%d)", int(r))
+}
+
+// Rejection names the partial at Position of the input, claimed by Signer
+type Rejection struct {
+	Signer   int
+	Position int
+	Reason   RejectReason
+}
+
+// CombineResult is the threshold signature together with the signers whose
+// partials were interpolated and the partials rejected on the way
+type CombineResult struct {
+	Sigma    bls.G2Jac
+	Signers  []int
+	Rejected []Rejection
+}
+
+// Rejections by a given reason, e.g. RejectBadProof as slashing evidence
+func (r *CombineResult) RejectedFor(reason RejectReason) []Rejection {
+	var out []Rejection
+	for _, rej := range r.Rejected {
+		if rej.Reason == reason {
+			out = append(out, rej)
+		}
+	}
+	return out
+}
+
+// CombineError reports a combination that did not reach t+1 valid partials
+type CombineError struct {
+	Valid    int
+	Needed   int
+	Rejected []Rejection
+}
+
+func (e *CombineError) Error() string {
+	return fmt.Sprintf("%v: %d of %d needed, %d rejected", ErrTooFewPartials, e.Valid, e.Needed, len(e.Rejected))
+}
+
+func (e *CombineError) Unwrap() error {
+	return ErrTooFewPartials
+}
+
+// Goes through the partials in order and keeps the first t+1 that pass all
+// checks; verify is only called on validated partials with a fresh, in-range index
+func selectPartials(n, t int, signers []int, sigmas []bls.G2Jac, verify func(pos int, sigma *bls.G2Affine) bool) ([]int, []bls.G2Affine, []Rejection) {
+	var used []int
+	var sigs []bls.G2Affine
+	var rejected []Rejection
+	seen := make(map[int]bool, t+1)
+
+	for pos, idx := range signers {
+		if len(used) == t+1 {
+			break
+		}
+		var sigma bls.G2Affine
+		reason := RejectReason(0)
+		switch {
+		case idx < 0 || idx >= n:
+			reason = RejectUnknownIndex
+		case seen[idx]:
+			reason = RejectDuplicate
+		case validG2(sigma.FromJacobian(&sigmas[pos])) != nil:
+			reason = RejectMalformedPoint
+		case !verify(pos, &sigma):
+			reason = RejectBadProof
+		}
+		if reason != 0 {
+			rejected = append(rejected, Rejection{Signer: idx, Position: pos, Reason: reason})
+			continue
+		}
+		seen[idx] = true
+		used = append(used, idx)
+		sigs = append(sigs, sigma)
+	}
+	return used, sigs, rejected
+}
+
+func combineResult(t int, combine func([]int, []bls.G2Affine) bls.G2Jac, used []int, sigs []bls.G2Affine, rejected []Rejection) (CombineResult, error) {
+	res := CombineResult{Signers: used, Rejected: rejected}
+	if len(used) <= t {
+		return res, &CombineError{Valid: len(used), Needed: t + 1, Rejected: rejected}
+	}
+	res.Sigma = combine(used, sigs)
+	return res, nil
+}
+
+/**************************
+	BOLDYREVA
+***************************/
+
+// Interpolates unverified partials after checking indices and points
+func (b *BLS) combineChecked(signers []int, sigmas []bls.G2Jac) (CombineResult, error) {
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(int, *bls.G2Affine) bool { return true })
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
+
+func (b *BLS) verifyCombineResult(msg bls.G2Affine, signers []int, sigmas []bls.G2Jac) (CombineResult, error) {
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(pos int, sigma *bls.G2Affine) bool {
+		return b.pairingVerify(msg, *sigma, b.pp.pKeys[signers[pos]])
+	})
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
+
+func (b *BLS) verifyCombineDleqResult(msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []Pf) (CombineResult, error) {
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(pos int, sigma *bls.G2Affine) bool {
+		return b.dleqVerify(msg, sigmas[pos], signers[pos], pfs[pos])
+	})
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
+
+/**************************
+	ADAPTIVE BLS
+***************************/
+
+func (b *ABLS) combineChecked(signers []int, sigmas []bls.G2Jac) (CombineResult, error) {
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(int, *bls.G2Affine) bool { return true })
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
+
+func (b *ABLS) verifyCombineResult(ro0Msg bls.G2Affine, ro1Msg bls.G2Affine, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) (CombineResult, error) {
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(pos int, sigma *bls.G2Affine) bool {
+		return b.proofVerify(ro0Msg, ro1Msg, sigmas[pos], signers[pos], pfs[pos])
+	})
+	return combineResult(b.t, b.combine, used, sigs, rejected)
+}
@@ -0,0 +1,96 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineResultBLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	ths := 3
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []Pf
+	for i := 0; i < n; i++ {
+		sigma, pf, _ := m.pSignDleq(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+
+	// Unknown index, duplicate, identity and a wrong signature
+	signers[0] = n
+	signers[2] = 1
+	sigmas[3] = bls.G2Jac{}
+	sigmas[4] = sigmas[5]
+
+	res, err := m.verifyCombineResult(roMsg, signers, sigmas)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	assert.Equal(t, []int{1, 5, 6, 7}, res.Signers)
+	assert.Equal(t, []Rejection{
+		{Signer: n, Position: 0, Reason: RejectUnknownIndex},
+		{Signer: 1, Position: 2, Reason: RejectDuplicate},
+		{Signer: 3, Position: 3, Reason: RejectMalformedPoint},
+		{Signer: 4, Position: 4, Reason: RejectBadProof},
+	}, res.Rejected)
+	assert.Equal(t, []Rejection{{Signer: 4, Position: 4, Reason: RejectBadProof}}, res.RejectedFor(RejectBadProof))
+
+	res, err = m.verifyCombineDleqResult(roMsg, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, res.Sigma))
+	assert.Len(t, res.Rejected, 4)
+
+	// Too few partials is a typed error
+	res, err = m.verifyCombineResult(roMsg, signers[:7], sigmas[:7])
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+	var cerr *CombineError
+	assert.ErrorAs(t, err, &cerr)
+	assert.Equal(t, 3, cerr.Valid)
+	assert.Equal(t, ths+1, cerr.Needed)
+	assert.Len(t, cerr.Rejected, 4)
+	assert.Equal(t, bls.G2Jac{}, res.Sigma)
+
+	// Unverified combination still checks indices and points
+	res, err = m.combineChecked(signers, sigmas)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 5, 6}, res.Signers)
+	assert.False(t, m.gverify(roMsg, res.Sigma))
+	assert.Equal(t, "malformed point", RejectMalformedPoint.String())
+}
+
+func TestCombineResultABLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	ths := 3
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i < n; i++ {
+		sigma, pf, _ := m.pSign(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	pfs[1] = pfs[2]
+
+	res, err := m.verifyCombineResult(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, res.Sigma))
+	assert.Equal(t, []int{0, 2, 3, 4}, res.Signers)
+	assert.Equal(t, []Rejection{{Signer: 1, Position: 1, Reason: RejectBadProof}}, res.Rejected)
+
+	_, err = m.verifyCombineResult(ro0Msg, ro1Msg, signers[:4], sigmas[:4], pfs[:4])
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+}
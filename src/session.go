@@ -0,0 +1,150 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+type Scheme uint8
+
+const (
+	SchemeABLS        Scheme = iota + 1 // adaptive BLS with sigma proofs
+	SchemeBoldyrevaI                    // Boldyreva with DLEQ proofs
+	SchemeBoldyrevaII                   // Boldyreva with pairing verification
+)
+
+type SessionID [32]byte
+
+// Envelope carries a partial signature together with everything it was
+// produced for. For the schemes with proofs, the proof transcript binds all
+// the envelope fields.
+//
+// SchemeBoldyrevaII envelopes carry no proof. The pairing check ties the
+// partial to the message and to the key of the signer slot, but not to the
+// session: the partial is a plain signature on H(msg), so it is valid in
+// every session that signs the same message. Binding the session into the
+// hashed message would change the combined signature as well. Callers that
+// need partials scoped to a session use SchemeBoldyrevaI or SchemeABLS.
+type Envelope struct {
+	Session SessionID
+	Digest  [32]byte
+	Signer  int
+	Scheme  Scheme
+	Sigma   bls.G2Jac
+	SigmaPf SigmaPf // only for SchemeABLS
+	Pf      Pf      // only for SchemeBoldyrevaI
+}
+
+func newEnvelope(session SessionID, msg Message, signer int, scheme Scheme) Envelope {
+	return Envelope{
+		Session: session,
+		Digest:  sha256.Sum256(msg),
+		Signer:  signer,
+		Scheme:  scheme,
+	}
+}
+
+// context returns the bytes the proof transcript is bound to
+func (e *Envelope) context() []byte {
+	ctx := make([]byte, 0, 11+32+32+8+1)
+	ctx = append(ctx, "TSS-SESSION"...)
+	ctx = append(ctx, e.Session[:]...)
+	ctx = append(ctx, e.Digest[:]...)
+	ctx = binary.BigEndian.AppendUint64(ctx, uint64(e.Signer))
+	return append(ctx, byte(e.Scheme))
+}
+
+// matches reports whether the envelope was produced for this combine request
+func (e *Envelope) matches(session SessionID, digest [32]byte, scheme Scheme, n int) bool {
+	return e.Session == session && e.Digest == digest && e.Scheme == scheme &&
+		e.Signer >= 0 && e.Signer < n
+}
+
+func (b *ABLS) pSignSession(session SessionID, msg Message, signer ABLSParty) (Envelope, error) {
+	env := newEnvelope(session, msg, signer.index, SchemeABLS)
+	sigma, pf, err := b.pSignCtx(env.context(), msg, signer)
+	if err != nil {
+		return Envelope{}, err
+	}
+	env.Sigma, env.SigmaPf = sigma, pf
+	return env, nil
+}
+
+// Combines the envelopes that belong to the session and message and whose
+// proofs verify; all others are dropped.
+func (b *ABLS) verifyCombineSession(session SessionID, msg Message, envs []Envelope) (bls.G2Jac, error) {
+	ro0Msg, ro1Msg, err := b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+
+	matched, signers, sigmas := matchEnvelopes(session, sha256.Sum256(msg), SchemeABLS, b.n, envs)
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(pos int, _ *bls.G2Affine) bool {
+		env := matched[pos]
+		return b.sigmaVerify(env.context(), ro0Msg, ro1Msg, env.Signer, env.Sigma, env.SigmaPf)
+	})
+	res, err := combineResult(b.t, b.combine, used, sigs, rejected)
+	return res.Sigma, err
+}
+
+// Keeps the envelopes produced for this combine request, in order
+func matchEnvelopes(session SessionID, digest [32]byte, scheme Scheme, n int, envs []Envelope) ([]*Envelope, []int, []bls.G2Jac) {
+	var matched []*Envelope
+	var signers []int
+	var sigmas []bls.G2Jac
+	for i := range envs {
+		env := &envs[i]
+		if !env.matches(session, digest, scheme, n) {
+			continue
+		}
+		matched = append(matched, env)
+		signers = append(signers, env.Signer)
+		sigmas = append(sigmas, env.Sigma)
+	}
+	return matched, signers, sigmas
+}
+
+func (b *BLS) psignSession(session SessionID, msg Message, signer BLSParty) (Envelope, error) {
+	env := newEnvelope(session, msg, signer.index, SchemeBoldyrevaII)
+	sigma, err := b.psign(msg, signer)
+	if err != nil {
+		return Envelope{}, err
+	}
+	env.Sigma = sigma
+	return env, nil
+}
+
+func (b *BLS) pSignDleqSession(session SessionID, msg Message, signer BLSParty) (Envelope, error) {
+	env := newEnvelope(session, msg, signer.index, SchemeBoldyrevaI)
+	sigma, pf, err := b.pSignDleqCtx(env.context(), msg, signer)
+	if err != nil {
+		return Envelope{}, err
+	}
+	env.Sigma, env.Pf = sigma, pf
+	return env, nil
+}
+
+// Combines the envelopes that belong to the session and message; the scheme
+// of every envelope must be the one given.
+func (b *BLS) verifyCombineSession(session SessionID, msg Message, scheme Scheme, envs []Envelope) (bls.G2Jac, error) {
+	roMsgAf, err := b.hashMsg(msg)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+
+	matched, signers, sigmas := matchEnvelopes(session, sha256.Sum256(msg), scheme, b.n, envs)
+	used, sigs, rejected := selectPartials(b.n, b.t, signers, sigmas, func(pos int, sigma *bls.G2Affine) bool {
+		env := matched[pos]
+		switch scheme {
+		case SchemeBoldyrevaI:
+			return b.cpVerify(env.context(), roMsgAf, env.Signer, env.Sigma, env.Pf)
+		case SchemeBoldyrevaII:
+			return b.pairingVerify(roMsgAf, *sigma, b.pp.pKeys[env.Signer])
+		}
+		return false
+	})
+	res, err := combineResult(b.t, b.combine, used, sigs, rejected)
+	return res.Sigma, err
+}
@@ -0,0 +1,125 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionABLS(t *testing.T) {
+	msg := []byte("hello world")
+	session := SessionID{1}
+	other := SessionID{2}
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	var envs []Envelope
+	for i := 0; i <= ths; i++ {
+		env, err := m.pSignSession(session, msg, m.pp.signers[i])
+		assert.NoError(t, err)
+		envs = append(envs, env)
+	}
+	msig, err := m.verifyCombineSession(session, msg, envs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, msig))
+
+	// The coordinator is running a different session or message
+	msig, _ = m.verifyCombineSession(other, msg, envs)
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+	msig, _ = m.verifyCombineSession(session, []byte("goodbye world"), envs)
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+
+	// Relabelling an envelope invalidates its proof
+	for _, tamper := range []func(e *Envelope){
+		func(e *Envelope) { e.Session = other },
+		func(e *Envelope) { e.Signer = (e.Signer + 1) % n },
+		func(e *Envelope) { e.Scheme = SchemeBoldyrevaI },
+	} {
+		env := envs[0]
+		tamper(&env)
+		assert.False(t, m.sigmaVerify(env.context(), ro0Msg, ro1Msg, env.Signer, env.Sigma, env.SigmaPf))
+	}
+
+	// An envelope from another session is dropped, leaving too few partials
+	envs[0], err = m.pSignSession(other, msg, m.pp.signers[0])
+	assert.NoError(t, err)
+	msig, err = m.verifyCombineSession(session, msg, envs)
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+
+	// A repeated envelope counts once
+	envs[0] = envs[1]
+	msig, err = m.verifyCombineSession(session, msg, envs)
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+	extra, err := m.pSignSession(session, msg, m.pp.signers[ths+1])
+	assert.NoError(t, err)
+	msig, err = m.verifyCombineSession(session, msg, append(envs, extra))
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(ro0Msg, msig))
+}
+
+func TestSessionBLS(t *testing.T) {
+	msg := []byte("hello world")
+	session := SessionID{1}
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	var dleqEnvs, pairEnvs []Envelope
+	for i := 0; i <= ths; i++ {
+		env, err := m.pSignDleqSession(session, msg, m.pp.signers[i])
+		assert.NoError(t, err)
+		dleqEnvs = append(dleqEnvs, env)
+
+		env, err = m.psignSession(session, msg, m.pp.signers[i])
+		assert.NoError(t, err)
+		pairEnvs = append(pairEnvs, env)
+	}
+
+	msig, err := m.verifyCombineSession(session, msg, SchemeBoldyrevaI, dleqEnvs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	msig, err = m.verifyCombineSession(session, msg, SchemeBoldyrevaII, pairEnvs)
+	assert.NoError(t, err)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	// Envelopes of the wrong scheme are rejected
+	msig, _ = m.verifyCombineSession(session, msg, SchemeBoldyrevaII, dleqEnvs)
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+
+	// A DLEQ proof does not transfer to another session
+	env := dleqEnvs[0]
+	env.Session = SessionID{2}
+	assert.False(t, m.cpVerify(env.context(), roMsg, env.Signer, env.Sigma, env.Pf))
+
+	// Pairing-checked partials are bound to the signer slot and the message,
+	// but only scoped by the message: they combine in another session
+	moved := append([]Envelope{}, pairEnvs...)
+	moved[0].Signer = ths + 1
+	msig, _ = m.verifyCombineSession(session, msg, SchemeBoldyrevaII, moved)
+	assert.True(t, msig.Equal(&bls.G2Jac{}))
+	other := SessionID{2}
+	moved = append([]Envelope{}, pairEnvs...)
+	for i := range moved {
+		moved[i].Session = other
+	}
+	msig, _ = m.verifyCombineSession(other, msg, SchemeBoldyrevaII, moved)
+	assert.True(t, m.gverify(roMsg, msig))
+
+	// Repeated envelopes count once, for either scheme
+	for scheme, envs := range map[Scheme][]Envelope{SchemeBoldyrevaI: dleqEnvs, SchemeBoldyrevaII: pairEnvs} {
+		dup := append([]Envelope{}, envs...)
+		dup[0] = dup[1]
+		msig, err = m.verifyCombineSession(session, msg, scheme, dup)
+		assert.ErrorIs(t, err, ErrTooFewPartials)
+		assert.True(t, msig.Equal(&bls.G2Jac{}))
+	}
+}
@@ -0,0 +1,111 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"io"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Streamed messages are first reduced to a SHA-256 digest under a pre-hash
+// label and then hashed to the curve under DSTs distinct from the ones used
+// for raw messages, so a stream and a raw message never share a hash point.
+const prehashLabel = "TSS-PREHASH-SHA256"
+
+// prehash digests the stream in constant memory
+func prehash(r io.Reader, dst string) (Message, error) {
+	h := sha256.New()
+	h.Write([]byte(prehashLabel))
+	h.Write([]byte{byte(len(dst))})
+	h.Write([]byte(dst))
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Hashes a streamed message to H0 and H1 with a single pass over the stream.
+// The policy of a streamed message is checked against this digest.
+func (b *ABLS) hashReader(r io.Reader) (Message, bls.G2Affine, bls.G2Affine, error) {
+	digest, err := prehash(r, "ABLS")
+	if err != nil {
+		return nil, bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	ro0Msg, err := bls.HashToG2(digest, []byte("DST0_PH"))
+	if err != nil {
+		return nil, bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	ro1Msg, err := bls.HashToG2(digest, []byte("DST1_PH"))
+	if err != nil {
+		return nil, bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	return digest, ro0Msg, ro1Msg, nil
+}
+
+func (b *ABLS) pSignReader(r io.Reader, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	digest, ro0Msg, ro1Msg, err := b.hashReader(r)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	if err := checkPolicy(b.policy, digest, signer.index); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	sigma, pf := b.signPoints(nil, ro0Msg, ro1Msg, signer)
+	return sigma, pf, nil
+}
+
+func (b *ABLS) gverifyReader(r io.Reader, sigma bls.G2Jac) (bool, error) {
+	_, ro0Msg, _, err := b.hashReader(r)
+	if err != nil {
+		return false, err
+	}
+	return b.gverify(ro0Msg, sigma), nil
+}
+
+// Pre-hashed messages are hashed under the DST of the CRS with a suffix
+func prehashDST(dst []byte) []byte {
+	return append(append([]byte{}, dst...), "-PH"...)
+}
+
+func (b *BLS) hashReader(r io.Reader) (Message, bls.G2Affine, error) {
+	digest, err := prehash(r, "BLS")
+	if err != nil {
+		return nil, bls.G2Affine{}, err
+	}
+	roMsg, err := bls.HashToG2(digest, prehashDST(b.crs.dst))
+	if err != nil {
+		return nil, bls.G2Affine{}, err
+	}
+	return digest, roMsg, nil
+}
+
+func (b *BLS) psignReader(r io.Reader, signer BLSParty) (bls.G2Jac, error) {
+	digest, roMsg, err := b.hashReader(r)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	if err := checkPolicy(b.policy, digest, signer.index); err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.signPoint(roMsg, signer), nil
+}
+
+func (b *BLS) pSignDleqReader(r io.Reader, signer BLSParty) (bls.G2Jac, Pf, error) {
+	digest, roMsg, err := b.hashReader(r)
+	if err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	if err := checkPolicy(b.policy, digest, signer.index); err != nil {
+		return bls.G2Jac{}, Pf{}, err
+	}
+	sigma, pf := b.signPointDleq(nil, roMsg, signer)
+	return sigma, pf, nil
+}
+
+func (b *BLS) gverifyReader(r io.Reader, sigma bls.G2Jac) (bool, error) {
+	_, roMsg, err := b.hashReader(r)
+	if err != nil {
+		return false, err
+	}
+	return b.gverify(roMsg, sigma), nil
+}
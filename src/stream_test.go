@@ -0,0 +1,115 @@
+package tss
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+// patternReader produces n bytes without ever holding them in memory
+type patternReader struct {
+	n int64
+}
+
+func (p *patternReader) Read(buf []byte) (int, error) {
+	if p.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(buf)) > p.n {
+		buf = buf[:p.n]
+	}
+	for i := range buf {
+		buf[i] = byte(p.n - int64(i))
+	}
+	p.n -= int64(len(buf))
+	return len(buf), nil
+}
+
+func TestStreamABLS(t *testing.T) {
+	size := int64(16 << 20)
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+
+	_, ro0Msg, ro1Msg, err := m.hashReader(&patternReader{size})
+	assert.NoError(t, err)
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		sigma, pf, err := m.pSignReader(&patternReader{size}, m.pp.signers[i])
+		assert.NoError(t, err)
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	msig := m.verifyCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+
+	ok, err := m.gverifyReader(&patternReader{size}, msig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = m.gverifyReader(&patternReader{size - 1}, msig)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStreamBLS(t *testing.T) {
+	msg := []byte("a message that is read a few bytes at a time")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []Pf
+	for i := 0; i <= ths; i++ {
+		// Chunking must not change the signature
+		sigma, err := m.psignReader(iotest.OneByteReader(bytes.NewReader(msg)), m.pp.signers[i])
+		assert.NoError(t, err)
+		sigmaDleq, pf, err := m.pSignDleqReader(bytes.NewReader(msg), m.pp.signers[i])
+		assert.NoError(t, err)
+		assert.True(t, sigma.Equal(&sigmaDleq))
+
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	_, roMsg, _ := m.hashReader(bytes.NewReader(msg))
+	msig := m.verifyCombineDleq(roMsg, signers, sigmas, pfs)
+
+	ok, err := m.gverifyReader(bytes.NewReader(msg), msig)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// A streamed message is domain separated from the same raw bytes
+	rawMsg, _ := m.hashMsg(msg)
+	assert.False(t, m.gverify(rawMsg, msig))
+
+	// and from streams hashed under the DST of another CRS
+	std := NewBLS(n, ths, GenBLSCRSStandard(n))
+	_, stdMsg, _ := std.hashReader(bytes.NewReader(msg))
+	assert.False(t, stdMsg.Equal(&roMsg))
+
+	// Read errors are surfaced
+	readErr := errors.New("disk on fire")
+	_, err = m.psignReader(iotest.ErrReader(readErr), m.pp.signers[0])
+	assert.ErrorIs(t, err, readErr)
+
+	// The policy sees the digest of the stream
+	digest, _, _ := m.hashReader(strings.NewReader("allowed"))
+	m.SetPolicy(NewAllowList(digest))
+	_, err = m.psignReader(strings.NewReader("allowed"), m.pp.signers[0])
+	assert.NoError(t, err)
+	_, err = m.psignReader(strings.NewReader("refused"), m.pp.signers[0])
+	assert.ErrorIs(t, err, ErrNotAllowed)
+}
@@ -0,0 +1,106 @@
+package tss
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// Data is encrypted to the identity of a future unchained beacon round; the
+// threshold signature the committee publishes for that round is the key.
+// Chained rounds can not be targeted as their message depends on the
+// previous, yet unknown, signature.
+const timelockVersion = 1
+
+var ErrRoundMismatch = errors.New("key is not the signature of the ciphertext's round")
+
+// TimelockCiphertext is encoded as version || round || U || V || W. The header
+// version || round is bound into the IBE encryption as associated data.
+type TimelockCiphertext struct {
+	Round uint64
+	ct    ibeCiphertext
+}
+
+func timelockHeader(round uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{timelockVersion}, round)
+}
+
+func (c *TimelockCiphertext) Bytes() []byte {
+	return append(timelockHeader(c.Round), c.ct.bytes()...)
+}
+
+func (c *TimelockCiphertext) SetBytes(data []byte) error {
+	if len(data) < 1+8 {
+		return ErrInvalidCiphertext
+	}
+	if data[0] != timelockVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidCiphertext, data[0])
+	}
+	c.Round = binary.BigEndian.Uint64(data[1:])
+	return c.ct.setBytes(data[9:])
+}
+
+func roundIdentity(round uint64) Message {
+	id, _ := RoundMessage(SchemeUnchained, round, nil)
+	return id
+}
+
+func (b *BLS) timelockEncrypt(round uint64, msg []byte) ([]byte, error) {
+	id, err := b.hashMsg(roundIdentity(round))
+	if err != nil {
+		return nil, err
+	}
+	ct, err := ibeEncrypt(b.pp.pk, b.crs.g1a, id, timelockHeader(round), msg)
+	if err != nil {
+		return nil, err
+	}
+	tc := TimelockCiphertext{Round: round, ct: ct}
+	return tc.Bytes(), nil
+}
+
+// Decrypts with the combined signature of the round the data was encrypted to.
+// A key that does not verify for the round in the header is rejected.
+func (b *BLS) timelockDecrypt(sigma bls.G2Jac, data []byte) ([]byte, error) {
+	var tc TimelockCiphertext
+	if err := tc.SetBytes(data); err != nil {
+		return nil, err
+	}
+	id, err := b.hashMsg(roundIdentity(tc.Round))
+	if err != nil {
+		return nil, err
+	}
+	if !b.gverify(id, sigma) {
+		return nil, ErrRoundMismatch
+	}
+	return ibeDecrypt(*new(bls.G2Affine).FromJacobian(&sigma), b.crs.g1a, timelockHeader(tc.Round), tc.ct)
+}
+
+func (b *ABLS) timelockEncrypt(round uint64, msg []byte) ([]byte, error) {
+	id, _, err := b.hashMsg(roundIdentity(round))
+	if err != nil {
+		return nil, err
+	}
+	ct, err := ibeEncrypt(b.pp.pk, b.crs.g1a, id, timelockHeader(round), msg)
+	if err != nil {
+		return nil, err
+	}
+	tc := TimelockCiphertext{Round: round, ct: ct}
+	return tc.Bytes(), nil
+}
+
+func (b *ABLS) timelockDecrypt(sigma bls.G2Jac, data []byte) ([]byte, error) {
+	var tc TimelockCiphertext
+	if err := tc.SetBytes(data); err != nil {
+		return nil, err
+	}
+	id, _, err := b.hashMsg(roundIdentity(tc.Round))
+	if err != nil {
+		return nil, err
+	}
+	if !b.gverify(id, sigma) {
+		return nil, ErrRoundMismatch
+	}
+	return ibeDecrypt(*new(bls.G2Affine).FromJacobian(&sigma), b.crs.g1a, timelockHeader(tc.Round), tc.ct)
+}
@@ -0,0 +1,104 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimelockBeacon(t *testing.T) {
+	secret := []byte("sealed bid: 42 coins")
+
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRSStandard(n))
+
+	ct, err := m.timelockEncrypt(2, secret)
+	assert.NoError(t, err)
+
+	beacons := runBeacon(t, &m, SchemeUnchained, 3)
+	sigmas := make([]bls.G2Jac, len(beacons))
+	for i, beacon := range beacons {
+		var sigmaAff bls.G2Affine
+		assert.NoError(t, sigmaAff.Unmarshal(beacon.Signature))
+		sigmas[i].FromAffine(&sigmaAff)
+	}
+
+	// Only the signature of round 2 decrypts
+	pt, err := m.timelockDecrypt(sigmas[1], ct)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	_, err = m.timelockDecrypt(sigmas[0], ct)
+	assert.ErrorIs(t, err, ErrRoundMismatch)
+	_, err = m.timelockDecrypt(sigmas[2], ct)
+	assert.ErrorIs(t, err, ErrRoundMismatch)
+
+	// A relabeled header no longer matches the key of the original round,
+	// and the key of the new round fails as the header is bound into r
+	relabeled := append([]byte{}, ct...)
+	relabeled[8] = 3
+	_, err = m.timelockDecrypt(sigmas[1], relabeled)
+	assert.ErrorIs(t, err, ErrRoundMismatch)
+	_, err = m.timelockDecrypt(sigmas[2], relabeled)
+	assert.ErrorIs(t, err, ErrDecrypt)
+
+	// Tampering is detected
+	for _, pos := range []int{9 + 47, len(ct) - 20, len(ct) - 1} {
+		tampered := append([]byte{}, ct...)
+		tampered[pos] ^= 1
+		_, err = m.timelockDecrypt(sigmas[1], tampered)
+		assert.Error(t, err)
+	}
+
+	var tc TimelockCiphertext
+	assert.NoError(t, tc.SetBytes(ct))
+	assert.Equal(t, uint64(2), tc.Round)
+	assert.Equal(t, ct, tc.Bytes())
+
+	unknown := append([]byte{}, ct...)
+	unknown[0] = timelockVersion + 1
+	_, err = m.timelockDecrypt(sigmas[1], unknown)
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	_, err = m.timelockDecrypt(sigmas[1], ct[:40])
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+}
+
+func TestTimelockABLS(t *testing.T) {
+	secret := []byte("")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+
+	ct, err := m.timelockEncrypt(7, secret)
+	assert.NoError(t, err)
+
+	combineRound := func(round uint64) bls.G2Jac {
+		msg := roundIdentity(round)
+		ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+		var signers []int
+		var sigmas []bls.G2Jac
+		var pfs []SigmaPf
+		for i := 0; i <= ths; i++ {
+			sigma, pf, err := m.pSign(msg, m.pp.signers[i])
+			assert.NoError(t, err)
+			signers = append(signers, i)
+			sigmas = append(sigmas, sigma)
+			pfs = append(pfs, pf)
+		}
+		return m.verifyCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	}
+
+	pt, err := m.timelockDecrypt(combineRound(7), ct)
+	assert.NoError(t, err)
+	assert.Equal(t, secret, pt)
+
+	_, err = m.timelockDecrypt(combineRound(8), ct)
+	assert.ErrorIs(t, err, ErrRoundMismatch)
+
+	relabeled := append([]byte{}, ct...)
+	relabeled[8] = 8
+	_, err = m.timelockDecrypt(combineRound(8), relabeled)
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
@@ -0,0 +1,78 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+/*
+* Fiat-Shamir transcript shared by all sigma protocols. Every entry is written
+* as len(name) || name || len(data) || data with 8-byte big-endian lengths, so
+* no two different sequences of entries give the same encoding. The first entry
+* is the protocol label. The challenge is derived with hash_to_field
+* (expand_message_xmd with SHA-256, 48 bytes per element), which reduces to Fr
+* with a bias of about 2^-128.
+ */
+
+const challengeDST = "TSS-FS-CHALLENGE-V1"
+
+type transcript struct {
+	buf []byte
+}
+
+func newTranscript(label string) *transcript {
+	t := &transcript{}
+	t.appendBytes("label", []byte(label))
+	return t
+}
+
+func (t *transcript) appendBytes(name string, data []byte) {
+	t.buf = binary.BigEndian.AppendUint64(t.buf, uint64(len(name)))
+	t.buf = append(t.buf, name...)
+	t.buf = binary.BigEndian.AppendUint64(t.buf, uint64(len(data)))
+	t.buf = append(t.buf, data...)
+}
+
+func (t *transcript) appendUint64(name string, v uint64) {
+	t.appendBytes(name, binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (t *transcript) appendG1(name string, pts ...bls.G1Affine) {
+	data := make([]byte, 0, len(pts)*bls.SizeOfG1AffineCompressed)
+	for i := range pts {
+		pBytes := pts[i].Bytes()
+		data = append(data, pBytes[:]...)
+	}
+	t.appendBytes(name, data)
+}
+
+func (t *transcript) appendG2(name string, pts ...bls.G2Affine) {
+	data := make([]byte, 0, len(pts)*bls.SizeOfG2AffineCompressed)
+	for i := range pts {
+		pBytes := pts[i].Bytes()
+		data = append(data, pBytes[:]...)
+	}
+	t.appendBytes(name, data)
+}
+
+func (t *transcript) challenge() fr.Element {
+	c, err := fr.Hash(t.buf, []byte(challengeDST), 1)
+	if err != nil {
+		// Only possible for a DST longer than 255 bytes
+		panic(err)
+	}
+	return c[0]
+}
+
+// Fingerprints bind proofs to the public parameters they were made under
+func crsFingerprint(label string, g1 []bls.G1Affine, g2 []bls.G2Affine, n int, dst []byte) [32]byte {
+	t := newTranscript(label)
+	t.appendG1("g1", g1...)
+	t.appendG2("g2", g2...)
+	t.appendUint64("n", uint64(n))
+	t.appendBytes("dst", dst)
+	return sha256.Sum256(t.buf)
+}
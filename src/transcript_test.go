@@ -0,0 +1,99 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranscriptEncoding(t *testing.T) {
+	chal := func(label string, entries ...string) string {
+		tr := newTranscript(label)
+		for i := 0; i+1 < len(entries); i += 2 {
+			tr.appendBytes(entries[i], []byte(entries[i+1]))
+		}
+		c := tr.challenge()
+		return c.String()
+	}
+
+	base := chal("L", "x", "ab", "y", "c")
+	assert.Equal(t, base, chal("L", "x", "ab", "y", "c"))
+
+	// Moving bytes across entry or name boundaries changes the challenge
+	assert.NotEqual(t, base, chal("L", "x", "a", "y", "bc"))
+	assert.NotEqual(t, base, chal("L", "x", "abc"))
+	assert.NotEqual(t, chal("L", "xa", "b"), chal("L", "x", "ab"))
+	assert.NotEqual(t, base, chal("L", "y", "c", "x", "ab"))
+
+	// Labels separate protocols
+	assert.NotEqual(t, base, chal("M", "x", "ab", "y", "c"))
+}
+
+func TestTranscriptBindingBLS(t *testing.T) {
+	n := 1 << 3
+	m := NewBLS(n, n/2, GenBLSCRS(n))
+	ctx := []byte("ctx")
+
+	roMsg, _ := m.hashMsg([]byte("message A"))
+	sigma, pf := m.signPointDleq(ctx, roMsg, m.pp.signers[0])
+	assert.True(t, m.cpVerify(ctx, roMsg, 0, sigma, pf))
+
+	// Another signer index, even with the same key
+	assert.False(t, m.cpVerify(ctx, roMsg, 1, sigma, pf))
+	other := m
+	other.pp.pKeys = append([]bls.G1Affine{}, m.pp.pKeys...)
+	other.pp.pKeys[1] = m.pp.pKeys[0]
+	assert.False(t, other.cpVerify(ctx, roMsg, 1, sigma, pf))
+
+	// Another context or message
+	assert.False(t, m.cpVerify([]byte("other"), roMsg, 0, sigma, pf))
+	roMsgB, _ := m.hashMsg([]byte("message B"))
+	assert.False(t, m.cpVerify(ctx, roMsgB, 0, sigma, pf))
+
+	// Another CRS or group key
+	other = m
+	other.crs.fp[0] ^= 1
+	assert.False(t, other.cpVerify(ctx, roMsg, 0, sigma, pf))
+	other = m
+	other.pp.pk = m.pp.pKeys[1]
+	assert.False(t, other.cpVerify(ctx, roMsg, 0, sigma, pf))
+
+	// The proof is bound to the partial signature it was made for
+	sigma2, _ := m.signPointDleq(ctx, roMsg, m.pp.signers[1])
+	assert.False(t, m.cpVerify(ctx, roMsg, 0, sigma2, pf))
+
+	// Same parameters, different CRS fingerprint
+	assert.NotEqual(t, GenBLSCRS(n).fp, GenBLSCRS(n).fp)
+}
+
+func TestTranscriptBindingABLS(t *testing.T) {
+	n := 1 << 3
+	m := NewABLS(n, n/2, GenABLSCRS(n))
+	ctx := []byte("ctx")
+
+	ro0, ro1, _ := m.hashMsg([]byte("message A"))
+	sigma, pf := m.signPoints(ctx, ro0, ro1, m.pp.signers[0])
+	assert.True(t, m.sigmaVerify(ctx, ro0, ro1, 0, sigma, pf))
+
+	assert.False(t, m.sigmaVerify(ctx, ro0, ro1, 1, sigma, pf))
+	other := m
+	other.pp.pKeys = append([]bls.G1Affine{}, m.pp.pKeys...)
+	other.pp.pKeys[1] = m.pp.pKeys[0]
+	assert.False(t, other.sigmaVerify(ctx, ro0, ro1, 1, sigma, pf))
+
+	assert.False(t, m.sigmaVerify([]byte("other"), ro0, ro1, 0, sigma, pf))
+	ro0B, ro1B, _ := m.hashMsg([]byte("message B"))
+	assert.False(t, m.sigmaVerify(ctx, ro0B, ro1B, 0, sigma, pf))
+	assert.False(t, m.sigmaVerify(ctx, ro1, ro0, 0, sigma, pf))
+
+	other = m
+	other.crs.fp[0] ^= 1
+	assert.False(t, other.sigmaVerify(ctx, ro0, ro1, 0, sigma, pf))
+	other = m
+	other.pp.pk = m.pp.pKeys[1]
+	assert.False(t, other.sigmaVerify(ctx, ro0, ro1, 0, sigma, pf))
+
+	sigma2, _ := m.signPoints(ctx, ro0, ro1, m.pp.signers[1])
+	assert.False(t, m.sigmaVerify(ctx, ro0, ro1, 0, sigma2, pf))
+}
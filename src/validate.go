@@ -0,0 +1,88 @@
+package tss
+
+import (
+	"errors"
+	"fmt"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+var (
+	ErrInvalidPoint    = errors.New("point is the identity or not in the prime-order subgroup")
+	ErrUnknownIndex    = errors.New("signer index out of range")
+	ErrDuplicateSigner = errors.New("duplicate signer index")
+)
+
+/*
+* Input validation shared by the verifiers and combine. Partial and combined
+* signatures must be non-identity elements of the prime-order subgroup of G2:
+* the identity verifies under any key, and a torsion component survives
+* interpolation and can pass DLEQ proofs once the challenge is ground modulo
+* its order. Keys are checked the same way in G1. Signer indices must lie in
+* [0, n) and be distinct, since a repeated index makes GetLagAt0 divide by
+* zero.
+ */
+
+func validG1(p *bls.G1Affine) error {
+	if p.IsInfinity() {
+		return fmt.Errorf("%w: identity", ErrInvalidPoint)
+	}
+	if !p.IsOnCurve() || !p.IsInSubGroup() {
+		return fmt.Errorf("%w: not in G1", ErrInvalidPoint)
+	}
+	return nil
+}
+
+func validG2(p *bls.G2Affine) error {
+	if p.IsInfinity() {
+		return fmt.Errorf("%w: identity", ErrInvalidPoint)
+	}
+	if !p.IsOnCurve() || !p.IsInSubGroup() {
+		return fmt.Errorf("%w: not in G2", ErrInvalidPoint)
+	}
+	return nil
+}
+
+// Checks a Jacobian point and hands back its affine form
+func validG2Jac(p *bls.G2Jac) (bls.G2Affine, error) {
+	var pAf bls.G2Affine
+	pAf.FromJacobian(p)
+	return pAf, validG2(&pAf)
+}
+
+func validIndex(n, idx int) error {
+	if idx < 0 || idx >= n {
+		return fmt.Errorf("%w: %d", ErrUnknownIndex, idx)
+	}
+	return nil
+}
+
+func validSigners(n int, signers []int) error {
+	seen := make(map[int]bool, len(signers))
+	for _, idx := range signers {
+		if err := validIndex(n, idx); err != nil {
+			return err
+		}
+		if seen[idx] {
+			return fmt.Errorf("%w: %d", ErrDuplicateSigner, idx)
+		}
+		seen[idx] = true
+	}
+	return nil
+}
+
+// The first t+1 partials are the ones combine interpolates
+func validQuorum(n, t int, signers []int, sigmas []bls.G2Affine) error {
+	if len(signers) <= t || len(sigmas) <= t {
+		return ErrTooFewPartials
+	}
+	if err := validSigners(n, signers[:t+1]); err != nil {
+		return err
+	}
+	for i := 0; i <= t; i++ {
+		if err := validG2(&sigmas[i]); err != nil {
+			return fmt.Errorf("partial %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidSigners(t *testing.T) {
+	assert.NoError(t, validSigners(8, []int{0, 7, 3}))
+	assert.ErrorIs(t, validSigners(8, []int{0, 8}), ErrUnknownIndex)
+	assert.ErrorIs(t, validSigners(8, []int{-1}), ErrUnknownIndex)
+	assert.ErrorIs(t, validSigners(8, []int{2, 5, 2}), ErrDuplicateSigner)
+}
+
+func TestValidG2(t *testing.T) {
+	_, gen2, _, _ := bls.Generators()
+	_, err := validG2Jac(&gen2)
+	assert.NoError(t, err)
+
+	_, err = validG2Jac(&bls.G2Jac{})
+	assert.ErrorIs(t, err, ErrInvalidPoint)
+
+	torsion := nonSubgroupG2()
+	assert.ErrorIs(t, validG2(&torsion), ErrInvalidPoint)
+
+	offCurve := torsion
+	offCurve.Y.Double(&offCurve.Y)
+	assert.ErrorIs(t, validG2(&offCurve), ErrInvalidPoint)
+}
+
+func TestValidationBLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	ths := 3
+	m := NewBLS(n, ths, GenBLSCRS(n))
+	roMsg, _ := m.hashMsg(msg)
+
+	sigma, pf, _ := m.pSignDleq(msg, m.pp.signers[0])
+	assert.True(t, m.pverify(roMsg, sigma, m.pp.pKeys[0]))
+	assert.True(t, m.pVerifyDleq(roMsg, sigma, 0, pf))
+
+	// The identity verifies under the identity key, and a torsion point is
+	// not a signature, whatever the proof says
+	torsion := nonSubgroupG2()
+	bad := []bls.G2Jac{{}, *new(bls.G2Jac).FromAffine(&torsion)}
+	bad[1].AddAssign(&sigma)
+	for _, s := range bad {
+		assert.False(t, m.pverify(roMsg, s, m.pp.pKeys[0]))
+		assert.False(t, m.pVerifyDleq(roMsg, s, 0, pf))
+		assert.False(t, m.gverify(roMsg, s))
+	}
+	assert.False(t, m.pverify(roMsg, bls.G2Jac{}, bls.G1Affine{}))
+
+	var signers []int
+	var sigmas []bls.G2Affine
+	for i := 0; i <= ths; i++ {
+		s, _ := m.psign(msg, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, *new(bls.G2Affine).FromJacobian(&s))
+	}
+	assert.True(t, m.gverify(roMsg, m.combine(signers, sigmas)))
+
+	// Duplicate and out of range indices are not interpolated
+	assert.Equal(t, bls.G2Jac{}, m.combine([]int{0, 1, 1, 2}, sigmas))
+	assert.Equal(t, bls.G2Jac{}, m.combine([]int{0, 1, 2, n}, sigmas))
+
+	// So are malformed partials among the first t+1
+	for _, s := range bad {
+		malformed := append([]bls.G2Affine{}, sigmas...)
+		malformed[2].FromJacobian(&s)
+		assert.Equal(t, bls.G2Jac{}, m.combine(signers, malformed))
+	}
+	assert.Equal(t, bls.G2Jac{}, m.combine(signers, sigmas[:ths]))
+
+	res, err := m.combineChecked([]int{0, 1, 1, 2}, []bls.G2Jac{{}, bad[1], bad[1], bad[1]})
+	assert.ErrorIs(t, err, ErrTooFewPartials)
+	assert.Equal(t, []Rejection{
+		{Signer: 0, Position: 0, Reason: RejectMalformedPoint},
+		{Signer: 1, Position: 1, Reason: RejectMalformedPoint},
+		{Signer: 1, Position: 2, Reason: RejectMalformedPoint},
+		{Signer: 2, Position: 3, Reason: RejectMalformedPoint},
+	}, res.Rejected)
+}
+
+func TestValidationABLS(t *testing.T) {
+	msg := []byte("hello world")
+
+	n := 1 << 3
+	ths := 3
+	m := NewABLS(n, ths, GenABLSCRS(n))
+	ro0Msg, ro1Msg, _ := m.hashMsg(msg)
+
+	sigma, pf, _ := m.pSign(msg, m.pp.signers[0])
+	assert.True(t, m.pVerify(ro0Msg, ro1Msg, sigma, 0, pf))
+
+	torsion := nonSubgroupG2()
+	bad := []bls.G2Jac{{}, *new(bls.G2Jac).FromAffine(&torsion)}
+	bad[1].AddAssign(&sigma)
+	for _, s := range bad {
+		assert.False(t, m.pVerify(ro0Msg, ro1Msg, s, 0, pf))
+		assert.False(t, m.gverify(ro0Msg, s))
+	}
+
+	sigmas := make([]bls.G2Affine, ths+1)
+	assert.Equal(t, bls.G2Jac{}, m.combine([]int{3, 2, 3, 1}, sigmas))
+	assert.Equal(t, bls.G2Jac{}, m.combine([]int{-1, 2, 3, 1}, sigmas))
+
+	signers := GetRange(0, ths+1)
+	for i := range sigmas {
+		s, _, _ := m.pSign(msg, m.pp.signers[i])
+		sigmas[i].FromJacobian(&s)
+	}
+	assert.True(t, m.gverify(ro0Msg, m.combine(signers, sigmas)))
+	for _, s := range bad {
+		malformed := append([]bls.G2Affine{}, sigmas...)
+		malformed[ths].FromJacobian(&s)
+		assert.Equal(t, bls.G2Jac{}, m.combine(signers, malformed))
+	}
+}
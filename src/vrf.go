@@ -0,0 +1,144 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+var ErrCombineFailed = errors.New("combined signature does not verify")
+
+// The threshold signature H(x)^s is unique for the group key, so hashing it
+// gives a verifiable random function of the input x. Inputs are hashed under
+// the signing DST with a "-VRF" suffix, otherwise an ordinary signature on x
+// would be the proof for x and reveal its output.
+const vrfOutputLabel = "TSS-VRF-OUTPUT"
+
+func vrfDST(dst []byte) []byte {
+	return append(append([]byte{}, dst...), "-VRF"...)
+}
+
+type VRFOutput [32]byte
+
+// VRFProof is the combined threshold signature on the input
+type VRFProof struct {
+	sigma bls.G2Affine
+}
+
+// VRFPublicKey holds everything needed to verify an evaluation: the group
+// key, the generator it is defined over and the DST inputs are hashed under.
+type VRFPublicKey struct {
+	pk  bls.G1Affine
+	g1  bls.G1Affine
+	dst []byte
+}
+
+func vrfOutput(sigma bls.G2Affine) VRFOutput {
+	sigBytes := sigma.Bytes()
+	h := sha256.New()
+	h.Write([]byte(vrfOutputLabel))
+	h.Write(sigBytes[:])
+
+	var out VRFOutput
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+func (b *BLS) vrfKey() VRFPublicKey {
+	return VRFPublicKey{pk: b.pp.pk, g1: b.crs.g1a, dst: vrfDST(b.crs.dst)}
+}
+
+func (b *BLS) vrfPoint(input Message) (bls.G2Affine, error) {
+	return bls.HashToG2(input, vrfDST(b.crs.dst))
+}
+
+// Partial evaluation of a signer; the policy sees the input
+func (b *BLS) vrfShare(input Message, signer BLSParty) (bls.G2Jac, error) {
+	if err := checkPolicy(b.policy, input, signer.index); err != nil {
+		return bls.G2Jac{}, err
+	}
+	roMsg, err := b.vrfPoint(input)
+	if err != nil {
+		return bls.G2Jac{}, err
+	}
+	return b.signPoint(roMsg, signer), nil
+}
+
+// Evaluates the VRF on input from the partial evaluations of the signers
+func (b *BLS) vrfEvaluate(input Message, signers []int, sigmas []bls.G2Jac) (VRFOutput, VRFProof, error) {
+	roMsg, err := b.vrfPoint(input)
+	if err != nil {
+		return VRFOutput{}, VRFProof{}, err
+	}
+	sigma := b.verifyCombine(roMsg, signers, sigmas)
+	if !b.gverify(roMsg, sigma) {
+		return VRFOutput{}, VRFProof{}, ErrCombineFailed
+	}
+	proof := VRFProof{*new(bls.G2Affine).FromJacobian(&sigma)}
+	return vrfOutput(proof.sigma), proof, nil
+}
+
+func (b *ABLS) vrfKey() VRFPublicKey {
+	return VRFPublicKey{pk: b.pp.pk, g1: b.crs.g1a, dst: vrfDST([]byte("DST0"))}
+}
+
+func (b *ABLS) vrfPoints(input Message) (bls.G2Affine, bls.G2Affine, error) {
+	ro0Msg, err := bls.HashToG2(input, vrfDST([]byte("DST0")))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	ro1Msg, err := bls.HashToG2(input, vrfDST([]byte("DST1")))
+	if err != nil {
+		return bls.G2Affine{}, bls.G2Affine{}, err
+	}
+	return ro0Msg, ro1Msg, nil
+}
+
+func (b *ABLS) vrfShare(input Message, signer ABLSParty) (bls.G2Jac, SigmaPf, error) {
+	if err := checkPolicy(b.policy, input, signer.index); err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	ro0Msg, ro1Msg, err := b.vrfPoints(input)
+	if err != nil {
+		return bls.G2Jac{}, SigmaPf{}, err
+	}
+	sigma, pf := b.signPoints(nil, ro0Msg, ro1Msg, signer)
+	return sigma, pf, nil
+}
+
+func (b *ABLS) vrfEvaluate(input Message, signers []int, sigmas []bls.G2Jac, pfs []SigmaPf) (VRFOutput, VRFProof, error) {
+	ro0Msg, ro1Msg, err := b.vrfPoints(input)
+	if err != nil {
+		return VRFOutput{}, VRFProof{}, err
+	}
+	sigma := b.verifyCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	if !b.gverify(ro0Msg, sigma) {
+		return VRFOutput{}, VRFProof{}, ErrCombineFailed
+	}
+	proof := VRFProof{*new(bls.G2Affine).FromJacobian(&sigma)}
+	return vrfOutput(proof.sigma), proof, nil
+}
+
+// VerifyVRF checks that proof is the threshold signature on input under the
+// group key, with the same pairing equation as gverify, and that output is
+// derived from it.
+func VerifyVRF(pk VRFPublicKey, input Message, output VRFOutput, proof VRFProof) bool {
+	return verifyWithKey(pk, input, proof.sigma) && vrfOutput(proof.sigma) == output
+}
+
+// Checks a combined signature outside of any BLS instance
+func verifyWithKey(pk VRFPublicKey, msg Message, sigma bls.G2Affine) bool {
+	if sigma.IsInfinity() || !sigma.IsInSubGroup() {
+		return false
+	}
+	roMsg, err := bls.HashToG2(msg, pk.dst)
+	if err != nil {
+		return false
+	}
+
+	var g1Inv bls.G1Affine
+	g1Inv.Neg(&pk.g1)
+	res, _ := bls.PairingCheck([]bls.G1Affine{pk.pk, g1Inv}, []bls.G2Affine{roMsg, sigma})
+	return res
+}
@@ -0,0 +1,104 @@
+package tss
+
+import (
+	"testing"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVRFBLS(t *testing.T) {
+	input := []byte("epoch 7 leader election")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewBLS(n, ths, GenBLSCRS(n))
+
+	// Two different quorums evaluate to the same output
+	var outputs []VRFOutput
+	for _, offset := range []int{0, n - ths - 1} {
+		var signers []int
+		var sigmas []bls.G2Jac
+		for i := offset; i <= offset+ths; i++ {
+			sigma, err := m.vrfShare(input, m.pp.signers[i])
+			assert.NoError(t, err)
+			signers = append(signers, i)
+			sigmas = append(sigmas, sigma)
+		}
+		output, proof, err := m.vrfEvaluate(input, signers, sigmas)
+		assert.NoError(t, err)
+		assert.True(t, VerifyVRF(m.vrfKey(), input, output, proof))
+		outputs = append(outputs, output)
+
+		roMsg, _ := m.vrfPoint(input)
+		assert.True(t, m.gverify(roMsg, *new(bls.G2Jac).FromAffine(&proof.sigma)))
+	}
+	assert.Equal(t, outputs[0], outputs[1])
+
+	// An ordinary signature on the input is not a VRF proof
+	var signers []int
+	var sigmas []bls.G2Jac
+	for i := 0; i <= ths; i++ {
+		sigma, _ := m.psign(input, m.pp.signers[i])
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+	}
+	roMsg, _ := m.hashMsg(input)
+	sig := m.verifyCombine(roMsg, signers, sigmas)
+	forged := VRFProof{*new(bls.G2Affine).FromJacobian(&sig)}
+	assert.False(t, VerifyVRF(m.vrfKey(), input, vrfOutput(forged.sigma), forged))
+	_, _, err := m.vrfEvaluate(input, signers, sigmas)
+	assert.ErrorIs(t, err, ErrCombineFailed)
+
+	// Too few partials give no output
+	sigma, _ := m.vrfShare(input, m.pp.signers[0])
+	_, _, err = m.vrfEvaluate(input, []int{0}, []bls.G2Jac{sigma})
+	assert.ErrorIs(t, err, ErrCombineFailed)
+}
+
+func TestVRFABLS(t *testing.T) {
+	input := []byte("epoch 7 leader election")
+
+	n := 1 << 3
+	ths := n / 2
+	m := NewABLS(n, ths, GenABLSCRS(n))
+
+	var signers []int
+	var sigmas []bls.G2Jac
+	var pfs []SigmaPf
+	for i := 0; i <= ths; i++ {
+		sigma, pf, err := m.vrfShare(input, m.pp.signers[i])
+		assert.NoError(t, err)
+		signers = append(signers, i)
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	output, proof, err := m.vrfEvaluate(input, signers, sigmas, pfs)
+	assert.NoError(t, err)
+
+	key := m.vrfKey()
+	assert.True(t, VerifyVRF(key, input, output, proof))
+	assert.False(t, VerifyVRF(key, []byte("epoch 8 leader election"), output, proof))
+
+	forged := output
+	forged[0] ^= 1
+	assert.False(t, VerifyVRF(key, input, forged, proof))
+
+	other := NewABLS(n, ths, GenABLSCRS(n))
+	assert.False(t, VerifyVRF(other.vrfKey(), input, output, proof))
+
+	assert.False(t, VerifyVRF(key, input, output, VRFProof{}))
+
+	// An ordinary signature on the input is not a VRF proof
+	ro0Msg, ro1Msg, _ := m.hashMsg(input)
+	sigmas = sigmas[:0]
+	pfs = pfs[:0]
+	for i := 0; i <= ths; i++ {
+		sigma, pf, _ := m.pSign(input, m.pp.signers[i])
+		sigmas = append(sigmas, sigma)
+		pfs = append(pfs, pf)
+	}
+	sig := m.verifyCombine(ro0Msg, ro1Msg, signers, sigmas, pfs)
+	sigAf := *new(bls.G2Affine).FromJacobian(&sig)
+	assert.False(t, VerifyVRF(key, input, vrfOutput(sigAf), VRFProof{sigAf}))
+}